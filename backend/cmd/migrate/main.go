@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -11,8 +12,8 @@ import (
 	"strconv"
 	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/joho/godotenv"
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/db"
 )
 
 // Migration represents a database migration
@@ -30,14 +31,14 @@ func main() {
 	)
 	flag.Parse()
 
-	// Load environment variables
-	err := godotenv.Load(*envFile)
+	// Load configuration; only the database settings are needed here
+	cfg, err := config.Load(*envFile)
 	if err != nil {
-		log.Printf("Warning: Error loading %s file: %v", *envFile, err)
+		log.Fatal(err)
 	}
 
 	// Database connection
-	db, err := connectDatabase()
+	db, err := connectDatabase(cfg.DB)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -71,30 +72,13 @@ func main() {
 	}
 }
 
-func connectDatabase() (*sql.DB, error) {
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-
-	if dbUser == "" || dbPassword == "" || dbHost == "" || dbPort == "" || dbName == "" {
-		return nil, fmt.Errorf("missing required database environment variables")
-	}
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
-
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := db.Ping(); err != nil {
-		return nil, err
+func connectDatabase(cfg config.DBConfig) (*sql.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database settings: %w", err)
 	}
 
-	return db, nil
+	// Waits for the database, so migrations can run right after it starts
+	return db.Open(context.Background(), cfg)
 }
 
 func runMigrations(db *sql.DB, migrationsDir string) error {
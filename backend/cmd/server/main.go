@@ -1,77 +1,123 @@
 package main
 
 import (
-	"database/sql"
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"os"
-	"strings"
+	"time"
 
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/db"
 	"protein-web-backend/internal/factory"
+	"protein-web-backend/internal/logging"
 	"protein-web-backend/internal/middleware"
-
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/joho/godotenv"
+	"protein-web-backend/internal/openapi"
+	"protein-web-backend/internal/router"
+	"protein-web-backend/internal/tracing"
 )
 
 func main() {
-	err := godotenv.Load()
+	// Configuration problems are logged as JSON too; the level is applied
+	// once the configuration is known.
+	slog.SetDefault(logging.New(os.Stderr, slog.LevelInfo))
+	cfg := config.MustLoad(".env")
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	logger := logging.New(os.Stderr, level)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fatal("failed to set up tracing", err)
 	}
 
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
+	cluster, err := db.OpenCluster(context.Background(), cfg.DB)
+	if err != nil {
+		fatal("failed to connect to database", err)
+	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4", dbUser, dbPassword, dbHost, dbPort, dbName)
-	db, err := sql.Open("mysql", dsn)
+	// Initialize application components using Factory
+	appFactory := factory.New(cluster, cfg)
+	repos, services, handlers := appFactory.NewAppComponents()
+
+	spec, err := openapi.Load()
 	if err != nil {
-		log.Fatal(err)
+		fatal("failed to load OpenAPI document", err)
+	}
+
+	r := router.New()
+	mountProbes(r, handlers.Health, appFactory.Metrics.Handler())
+	mountV1(r.Group("/api/v1"), handlers.V1, appFactory.NewAuthMiddleware(), appFactory.NewIdempotencyMiddleware(repos), spec, cfg.HTTP.RequestTimeout)
+
+	// Every route must be documented so clients can generate types from the spec
+	if err := spec.CheckRoutes(r.Routes()); err != nil {
+		fatal("routes do not match the OpenAPI document", err)
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatal(err)
+	if !cfg.HTTP.UnversionedSunset.After(unversionedDeprecatedAt) {
+		fatal("invalid configuration", errors.New("API_UNVERSIONED_SUNSET must be after the deprecation of the unversioned routes"))
 	}
 
-	// Initialize application components using Factory
-	appFactory := factory.New(db)
-	_, _, handlers := appFactory.NewAppComponents()
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/api/users", handlers.User.GetUsers)
-	mux.HandleFunc("/api/register", handlers.User.RegisterUser)
-	mux.HandleFunc("/api/login", handlers.User.LoginUser)
-
-	// Review endpoints
-	mux.HandleFunc("/api/reviews", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			// Apply auth middleware to POST requests
-			middleware.AuthMiddleware(handlers.Review.CreateReview)(w, r)
-		case http.MethodGet:
-			handlers.Review.GetAllReviews(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
-	mux.HandleFunc("/api/reviews/", handlers.Review.GetReview)
-	mux.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasSuffix(r.URL.Path, "/reviews") {
-			handlers.Review.GetUserReviews(w, r)
-		} else {
-			http.Error(w, "Not found", http.StatusNotFound)
-		}
-	})
+	var handler http.Handler = r
+	if cfg.HTTP.ValidateResponses {
+		handler = spec.Conformance(handler)
+	}
+	// Tracing, AccessLog and Metrics read the matched route from the
+	// request, so they stay directly outside the router
+	handler = middleware.Metrics(appFactory.Metrics.HTTPRequestDuration)(handler)
+	handler = middleware.AccessLog(handler)
+	handler = middleware.Tracing(handler)
+	handler = middleware.UnversionedAlias("/api", "v1", unversionedDeprecatedAt, cfg.HTTP.UnversionedSunset)(handler)
+	handler = middleware.RequestID(logger)(handler)
+	corsHandler := middleware.CORSMiddleware(handler)
 
-	corsHandler := middleware.CORSMiddleware(mux)
+	srv := newServer(cfg.HTTP, corsHandler, logger)
+	// Event streams never go idle, so they are ended when shutdown begins
+	srv.RegisterOnShutdown(appFactory.Close)
 
-	fmt.Println("Server is running on port 8080")
-	if err := http.ListenAndServe(":8080", corsHandler); err != nil {
-		log.Fatal(err)
+	// Production deployments may run cmd/worker instead
+	stopWorker := func(time.Duration) bool { return true }
+	if cfg.Jobs.RunInServer {
+		stopWorker = appFactory.NewJobWorker(repos, services).Start(context.Background())
 	}
+
+	// The relay always runs here: realtime subscribers are in this process
+	stopRelay := appFactory.NewOutboxRelay(repos, services).Start(context.Background())
+
+	err = serve(srv, cfg.HTTP)
+
+	if !stopRelay(cfg.Outbox.ShutdownTimeout) {
+		slog.Warn("outbox relay still publishing at shutdown; the events are published again after their lease expires")
+	}
+	if !stopWorker(cfg.Jobs.ShutdownTimeout) {
+		slog.Warn("jobs still running at shutdown; they are retried after their lease expires")
+	}
+	// Requests have finished (or were cut off); release what they used
+	appFactory.Close()
+	if closeErr := appFactory.Cache.Close(); closeErr != nil {
+		slog.Error("failed to close cache", "error", closeErr)
+	}
+	if appFactory.Broker != nil {
+		if closeErr := appFactory.Broker.Close(); closeErr != nil {
+			slog.Error("failed to close event broker", "error", closeErr)
+		}
+	}
+	if closeErr := cluster.Close(); closeErr != nil {
+		slog.Error("failed to close database", "error", closeErr)
+	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+		slog.Error("failed to flush traces", "error", flushErr)
+	}
+	cancel()
+	if err != nil {
+		fatal("server failed", err)
+	}
+	slog.Info("server stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
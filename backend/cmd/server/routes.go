@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"protein-web-backend/internal/factory"
+	"protein-web-backend/internal/handler"
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/openapi"
+	"protein-web-backend/internal/router"
+)
+
+// mountProbes registers the health, build-info and metrics endpoints at the
+// root. They bypass the API middleware: no authentication, and rate limits
+// or deadlines added to the API must not make the orchestrator restart a
+// healthy instance.
+func mountProbes(root *router.Router, h *handler.HealthHandler, metrics http.Handler) {
+	root.Get("/healthz", h.Liveness)
+	root.Get("/readyz", h.Readiness)
+	root.Get("/version", h.Version)
+	root.Handle(http.MethodGet, "/metrics", metrics)
+}
+
+// unversionedDeprecatedAt is when the /api/... aliases of /api/v1/... were
+// deprecated. They are removed at the sunset date (http.unversioned_sunset).
+var unversionedDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// mountV1 registers the v1 API on root, which is mounted at /api/v1.
+// idempotent is applied to the POST endpoints that create resources.
+func mountV1(root *router.Router, h *factory.V1Handlers, auth *middleware.Auth, idempotent func(http.Handler) http.Handler, spec *openapi.Spec, timeout time.Duration) {
+	// The event stream is long-lived, so it is the one route without a deadline
+	root.Group("", auth.Required).Get("/stream", h.Stream.Stream)
+
+	api := root.Group("", middleware.Timeout(timeout))
+	api.Get("/openapi.json", spec.ServeHTTP)
+
+	// Users and authentication
+	api.Get("/users", h.User.GetUsers)
+	api.Group("", idempotent).Post("/register", h.User.RegisterUser)
+	api.Post("/login", h.User.LoginUser)
+
+	// Public reads; a valid token lets authors see their hidden reviews.
+	// Clients revalidate with If-None-Match and get 304 when nothing changed.
+	public := api.Group("", auth.Optional, middleware.ETag)
+	public.Get("/reviews", h.Review.GetAllReviews)
+	public.Get("/reviews/{id}", h.Review.GetReview)
+	public.Get("/users/{id}/reviews", h.Review.GetUserReviews)
+
+	// Authenticated endpoints
+	authed := api.Group("", auth.Required)
+	authed.Group("", idempotent).Post("/reviews", h.Review.CreateReview)
+	// Writes to a review require If-Match with its ETag
+	authed.Patch("/reviews/{id}", h.Review.UpdateReview)
+	authed.Delete("/reviews/{id}", h.Review.DeleteReview)
+	authed.Delete("/reviews/{id}/images/{imageId}", h.Review.DeleteReviewImage)
+	authed.Post("/reviews/{id}/report", h.Report.ReportReview)
+	authed.Get("/me/export", h.Privacy.ExportData)
+	authed.Post("/me/erase", h.Privacy.EraseAccount)
+
+	// Administration
+	admin := api.Group("/admin", auth.Admin)
+	admin.Get("/reports", h.Report.ListReports)
+	admin.Post("/reports/{id}/dismiss", h.Report.DismissReport)
+	admin.Post("/reviews/{id}/hide", h.Report.HideReview)
+	admin.Post("/reviews/{id}/restore", h.Report.RestoreReview)
+	admin.Post("/reviews/{id}/undelete", h.Review.RestoreReview)
+	admin.Get("/moderation-results", h.Moderation.ListResults)
+	admin.Delete("/users/{id}", h.User.DeleteUser)
+	admin.Post("/users/{id}/undelete", h.User.RestoreUser)
+	admin.Post("/users/{id}/erase", h.Privacy.EraseUser)
+	admin.Get("/audit-log", h.Audit.ListAuditLog)
+	admin.Get("/webhooks", h.Webhook.ListWebhooks)
+	admin.Post("/webhooks", h.Webhook.CreateWebhook)
+	admin.Get("/webhooks/{id}", h.Webhook.GetWebhook)
+	admin.Patch("/webhooks/{id}", h.Webhook.UpdateWebhook)
+	admin.Delete("/webhooks/{id}", h.Webhook.DeleteWebhook)
+	admin.Get("/webhooks/{id}/deliveries", h.Webhook.ListDeliveries)
+	admin.Post("/webhooks/{id}/ping", h.Webhook.PingWebhook)
+}
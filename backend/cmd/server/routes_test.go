@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/auth"
+	"protein-web-backend/internal/factory"
+	"protein-web-backend/internal/handler"
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/openapi"
+	"protein-web-backend/internal/realtime"
+	"protein-web-backend/internal/router"
+	"protein-web-backend/internal/service"
+)
+
+// missingID is the ID the fake services report as not found
+const missingID = 404
+
+var testTime = time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+func found(id int, resource string) error {
+	if id == missingID {
+		return &service.NotFoundError{Resource: resource}
+	}
+	return nil
+}
+
+func testUser(id int) *model.User {
+	name := "user"
+	return &model.User{ID: id, Email: "user@example.com", Name: &name, Role: model.RoleUser, CreatedAt: testTime, UpdatedAt: testTime}
+}
+
+func testReview(id int) *model.Review {
+	return &model.Review{
+		ID:                id,
+		UserID:            1,
+		User:              testUser(1),
+		ProteinPerServing: "20g",
+		PricePerServing:   "100円",
+		Comment:           "good",
+		Version:           3,
+		Images:            []model.ReviewImage{{ID: 1, ReviewID: id, ImageURL: "https://example.com/a.png", CreatedAt: testTime}},
+		CreatedAt:         testTime,
+		UpdatedAt:         testTime,
+	}
+}
+
+func testWebhook(id int) *model.Webhook {
+	return &model.Webhook{ID: id, URL: "https://example.com/hook", EventTypes: []string{model.EventReviewCreated}, Active: true, CreatedAt: testTime, UpdatedAt: testTime}
+}
+
+type fakeUserService struct{ service.UserService }
+
+func (fakeUserService) GetUsers(ctx context.Context) ([]model.User, error) {
+	return []model.User{*testUser(1)}, nil
+}
+
+func (fakeUserService) RegisterUser(ctx context.Context, email, password, name string) (*model.User, error) {
+	if email == "taken@example.com" {
+		return nil, &service.ConflictError{Message: "email already registered"}
+	}
+	return testUser(2), nil
+}
+
+func (fakeUserService) LoginUser(ctx context.Context, email, password string) (string, *model.User, error) {
+	if password != "password123" {
+		return "", nil, &service.UnauthorizedError{Message: "invalid credentials"}
+	}
+	return "token", testUser(1), nil
+}
+
+func (fakeUserService) DeleteUser(ctx context.Context, actorID, id int) error {
+	return found(id, "user")
+}
+
+func (fakeUserService) RestoreUser(ctx context.Context, actorID, id int) error {
+	return found(id, "user")
+}
+
+type fakeReviewService struct{ service.ReviewService }
+
+func (fakeReviewService) CreateReview(ctx context.Context, userID int, req *model.CreateReviewRequest) (*model.Review, error) {
+	if req.Comment == "rejected" {
+		return nil, &service.ContentRejectedError{Reasons: []string{"banned word"}}
+	}
+	return testReview(1), nil
+}
+
+func (fakeReviewService) GetReview(ctx context.Context, id, viewerID int) (*model.Review, error) {
+	if err := found(id, "review"); err != nil {
+		return nil, err
+	}
+	return testReview(id), nil
+}
+
+func (fakeReviewService) GetAllReviews(ctx context.Context, viewerID, limit, offset int) ([]*model.Review, error) {
+	return []*model.Review{testReview(1)}, nil
+}
+
+func (fakeReviewService) GetUserReviews(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error) {
+	if err := found(userID, "user"); err != nil {
+		return nil, err
+	}
+	return []*model.Review{testReview(1)}, nil
+}
+
+// checkVersion mimics the service: the fake reviews are at version 3
+func checkVersion(id int, ifMatch service.Precondition) error {
+	if err := found(id, "review"); err != nil {
+		return err
+	}
+	if !ifMatch.Any && !contains(ifMatch.Versions, 3) {
+		return &service.PreconditionFailedError{Message: "review has been modified since it was read"}
+	}
+	return nil
+}
+
+func contains(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (fakeReviewService) UpdateReview(ctx context.Context, actorID, id int, ifMatch service.Precondition, req *model.UpdateReviewRequest) (*model.Review, error) {
+	if err := checkVersion(id, ifMatch); err != nil {
+		return nil, err
+	}
+	review := testReview(id)
+	review.Version++
+	return review, nil
+}
+
+func (fakeReviewService) DeleteReview(ctx context.Context, actorID, id int, ifMatch service.Precondition) error {
+	return checkVersion(id, ifMatch)
+}
+
+func (fakeReviewService) DeleteReviewImage(ctx context.Context, actorID, reviewID, imageID int, ifMatch service.Precondition) error {
+	if err := checkVersion(reviewID, ifMatch); err != nil {
+		return err
+	}
+	return found(imageID, "review image")
+}
+
+func (fakeReviewService) RestoreReview(ctx context.Context, actorID, id int) error {
+	return found(id, "review")
+}
+
+type fakeReportService struct{ service.ReportService }
+
+func (fakeReportService) ReportReview(ctx context.Context, reporterID, reviewID int, req *model.CreateReportRequest) (*model.Report, error) {
+	if err := found(reviewID, "review"); err != nil {
+		return nil, err
+	}
+	return &model.Report{ID: 1, ReviewID: reviewID, ReporterID: reporterID, Reason: req.Reason, Status: model.ReportStatusOpen, CreatedAt: testTime}, nil
+}
+
+func (fakeReportService) ListReports(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error) {
+	return []*model.Report{{ID: 1, ReviewID: 1, ReporterID: 2, Reason: model.ReportReason("spam"), Status: model.ReportStatusOpen, CreatedAt: testTime, Review: testReview(1)}}, nil
+}
+
+func (fakeReportService) DismissReport(ctx context.Context, adminID, reportID int) error {
+	return found(reportID, "report")
+}
+
+func (fakeReportService) HideReview(ctx context.Context, adminID, reviewID int) error {
+	return found(reviewID, "review")
+}
+
+func (fakeReportService) RestoreReview(ctx context.Context, adminID, reviewID int) error {
+	return found(reviewID, "review")
+}
+
+type fakeModerationService struct{ service.ModerationService }
+
+func (fakeModerationService) ListResults(ctx context.Context, action string, limit, offset int) ([]*model.ModerationResult, error) {
+	reviewID := 1
+	return []*model.ModerationResult{{ID: 1, ReviewID: &reviewID, UserID: 1, Action: "flag", OriginalText: "text", Decisions: json.RawMessage(`[]`), CreatedAt: testTime}}, nil
+}
+
+type fakeAuditService struct{ service.AuditService }
+
+func (fakeAuditService) List(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLogEntry, error) {
+	actorID := 1
+	return []*model.AuditLogEntry{{ID: 1, ActorID: &actorID, EntityType: model.AuditEntityReview, EntityID: 1, Action: model.AuditActionDelete, Diff: json.RawMessage(`{}`), CreatedAt: testTime}}, nil
+}
+
+type fakePrivacyService struct{ service.PrivacyService }
+
+func (fakePrivacyService) ExportUserData(ctx context.Context, userID int) (*model.UserDataExport, error) {
+	return &model.UserDataExport{ExportedAt: testTime, Account: testUser(userID)}, nil
+}
+
+func (fakePrivacyService) WriteArchive(ctx context.Context, export *model.UserDataExport, w io.Writer) error {
+	_, err := io.WriteString(w, "PK")
+	return err
+}
+
+func (fakePrivacyService) EraseOwnAccount(ctx context.Context, userID int, password string) error {
+	if password != "password123" {
+		return &service.ForbiddenError{Message: "password does not match"}
+	}
+	return nil
+}
+
+func (fakePrivacyService) EraseUser(ctx context.Context, actorID, userID int) error {
+	return found(userID, "user")
+}
+
+type fakeWebhookService struct{ service.WebhookService }
+
+func (fakeWebhookService) CreateWebhook(ctx context.Context, actorID int, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	hook := testWebhook(1)
+	hook.Secret = "0123456789abcdef"
+	return hook, nil
+}
+
+func (fakeWebhookService) ListWebhooks(ctx context.Context, limit, offset int) ([]*model.Webhook, error) {
+	return []*model.Webhook{testWebhook(1)}, nil
+}
+
+func (fakeWebhookService) GetWebhook(ctx context.Context, id int) (*model.Webhook, error) {
+	if err := found(id, "webhook"); err != nil {
+		return nil, err
+	}
+	return testWebhook(id), nil
+}
+
+func (fakeWebhookService) UpdateWebhook(ctx context.Context, actorID, id int, req *model.UpdateWebhookRequest) (*model.Webhook, error) {
+	if err := found(id, "webhook"); err != nil {
+		return nil, err
+	}
+	return testWebhook(id), nil
+}
+
+func (fakeWebhookService) DeleteWebhook(ctx context.Context, actorID, id int) error {
+	return found(id, "webhook")
+}
+
+func testDelivery(id int) *model.WebhookDelivery {
+	status := 204
+	return &model.WebhookDelivery{ID: 1, WebhookID: id, EventID: "abc", EventType: model.EventReviewCreated, Attempt: 1, Success: true, StatusCode: &status, DurationMs: 12, CreatedAt: testTime}
+}
+
+func (fakeWebhookService) ListDeliveries(ctx context.Context, id, limit, offset int) ([]*model.WebhookDelivery, error) {
+	if err := found(id, "webhook"); err != nil {
+		return nil, err
+	}
+	return []*model.WebhookDelivery{testDelivery(id)}, nil
+}
+
+func (fakeWebhookService) PingWebhook(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	if err := found(id, "webhook"); err != nil {
+		return nil, err
+	}
+	return testDelivery(id), nil
+}
+
+type fakeHealthService struct{ ready bool }
+
+func (s fakeHealthService) Readiness(ctx context.Context) *model.Readiness {
+	if !s.ready {
+		return &model.Readiness{Status: model.HealthUnavailable, Checks: map[string]string{"database": "unreachable"}}
+	}
+	return &model.Readiness{Status: model.HealthOK, Checks: map[string]string{"database": model.HealthOK}}
+}
+
+// passThrough stands in for the idempotency middleware, which has its own tests
+func passThrough(next http.Handler) http.Handler {
+	return next
+}
+
+// newTestServer mounts the real routes on handlers backed by fake services
+func newTestServer(t *testing.T, spec *openapi.Spec, tokens *auth.Tokens, ready bool) *router.Router {
+	t.Helper()
+	v1 := &factory.V1Handlers{
+		User:       handler.NewUserHandler(fakeUserService{}, time.Hour),
+		Review:     handler.NewReviewHandler(fakeReviewService{}),
+		Stream:     handler.NewStreamHandler(realtime.NewMemoryHub(1)),
+		Report:     handler.NewReportHandler(fakeReportService{}),
+		Moderation: handler.NewModerationHandler(fakeModerationService{}),
+		Audit:      handler.NewAuditHandler(fakeAuditService{}),
+		Privacy:    handler.NewPrivacyHandler(fakePrivacyService{}),
+		Webhook:    handler.NewWebhookHandler(fakeWebhookService{}),
+	}
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		io.WriteString(w, "# HELP up\n")
+	})
+
+	r := router.New()
+	mountProbes(r, handler.NewHealthHandler(fakeHealthService{ready: ready}), metrics)
+	mountV1(r.Group("/api/v1"), v1, middleware.NewAuth(tokens), passThrough, spec, time.Second)
+	if err := spec.CheckRoutes(r.Routes()); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+type routeCase struct {
+	name  string
+	route string // operation in openapi.json, "METHOD /pattern"
+	path  string // request target; defaults to the route's pattern
+	as    string // "", "user" or "admin"
+	head  map[string]string
+	body  string
+
+	status  int
+	headers []string // headers the response must set
+}
+
+func TestRoutesConformToOpenAPI(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := auth.NewTokens("0123456789abcdef0123456789abcdef", time.Hour)
+	userToken, _ := tokens.Issue(&model.User{ID: 1, Role: model.RoleUser})
+	adminToken, _ := tokens.Issue(&model.User{ID: 9, Role: model.RoleAdmin})
+	srv := newTestServer(t, spec, tokens, true)
+
+	ifMatch := map[string]string{"If-Match": `"v3"`}
+	stale := map[string]string{"If-Match": `"v2"`}
+	review := `{"proteinPerServing":"20g","pricePerServing":"100円","comment":"good","images":["https://example.com/a.png"]}`
+	webhook := `{"url":"https://example.com/hook","eventTypes":["review.created"]}`
+
+	cases := []routeCase{
+		{name: "list users", route: "GET /api/v1/users", status: 200},
+		{name: "register", route: "POST /api/v1/register", body: `{"email":"new@example.com","password":"password123","name":"new"}`, status: 201},
+		{name: "register invalid email", route: "POST /api/v1/register", body: `{"email":"nope","password":"password123"}`, status: 400},
+		{name: "register taken", route: "POST /api/v1/register", body: `{"email":"taken@example.com","password":"password123"}`, status: 409},
+		{name: "register too large", route: "POST /api/v1/register", body: `{"email":"` + strings.Repeat("a", 1<<20) + `"}`, status: 413},
+		{name: "login", route: "POST /api/v1/login", body: `{"email":"user@example.com","password":"password123"}`, status: 200},
+		{name: "login wrong password", route: "POST /api/v1/login", body: `{"email":"user@example.com","password":"wrong"}`, status: 401},
+
+		{name: "list reviews", route: "GET /api/v1/reviews", status: 200, headers: []string{"ETag"}},
+		{name: "list reviews bad token", route: "GET /api/v1/reviews", head: map[string]string{"Authorization": "Bearer nope"}, status: 401},
+		{name: "create review", route: "POST /api/v1/reviews", as: "user", body: review, status: 201, headers: []string{"ETag"}},
+		{name: "create review anonymous", route: "POST /api/v1/reviews", body: review, status: 401},
+		{name: "create review rejected", route: "POST /api/v1/reviews", as: "user", body: `{"proteinPerServing":"1","pricePerServing":"1","comment":"rejected"}`, status: 422},
+		{name: "get review", route: "GET /api/v1/reviews/{id}", path: "/api/v1/reviews/1", status: 200, headers: []string{"ETag"}},
+		{name: "get review not modified", route: "GET /api/v1/reviews/{id}", path: "/api/v1/reviews/1", head: map[string]string{"If-None-Match": `"v3"`}, status: 304, headers: []string{"ETag"}},
+		{name: "get review bad id", route: "GET /api/v1/reviews/{id}", path: "/api/v1/reviews/x", status: 400},
+		{name: "get review missing", route: "GET /api/v1/reviews/{id}", path: "/api/v1/reviews/404", status: 404},
+		{name: "update review", route: "PATCH /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", head: ifMatch, body: `{"comment":"better"}`, status: 200, headers: []string{"ETag"}},
+		{name: "update review without If-Match", route: "PATCH /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", body: `{"comment":"better"}`, status: 428},
+		{name: "update review stale", route: "PATCH /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", head: stale, body: `{"comment":"better"}`, status: 412},
+		{name: "update review empty comment", route: "PATCH /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", head: ifMatch, body: `{"comment":""}`, status: 400},
+		{name: "delete review", route: "DELETE /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", head: ifMatch, status: 204},
+		{name: "delete review any version", route: "DELETE /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", head: map[string]string{"If-Match": "*"}, status: 204},
+		{name: "delete review without If-Match", route: "DELETE /api/v1/reviews/{id}", path: "/api/v1/reviews/1", as: "user", status: 428},
+		{name: "delete review missing", route: "DELETE /api/v1/reviews/{id}", path: "/api/v1/reviews/404", as: "user", head: ifMatch, status: 404},
+		{name: "delete image", route: "DELETE /api/v1/reviews/{id}/images/{imageId}", path: "/api/v1/reviews/1/images/1", as: "user", head: ifMatch, status: 204},
+		{name: "delete image stale", route: "DELETE /api/v1/reviews/{id}/images/{imageId}", path: "/api/v1/reviews/1/images/1", as: "user", head: stale, status: 412},
+		{name: "report review", route: "POST /api/v1/reviews/{id}/report", path: "/api/v1/reviews/1/report", as: "user", body: `{"reason":"spam"}`, status: 201},
+		{name: "report review bad reason", route: "POST /api/v1/reviews/{id}/report", path: "/api/v1/reviews/1/report", as: "user", body: `{"reason":"boring"}`, status: 400},
+		{name: "user reviews", route: "GET /api/v1/users/{id}/reviews", path: "/api/v1/users/1/reviews", status: 200, headers: []string{"ETag"}},
+		{name: "user reviews missing", route: "GET /api/v1/users/{id}/reviews", path: "/api/v1/users/404/reviews", status: 404},
+
+		{name: "export", route: "GET /api/v1/me/export", as: "user", status: 200, headers: []string{"Content-Disposition"}},
+		{name: "export anonymous", route: "GET /api/v1/me/export", status: 401},
+		{name: "erase own account", route: "POST /api/v1/me/erase", as: "user", body: `{"password":"password123"}`, status: 204},
+		{name: "erase own account wrong password", route: "POST /api/v1/me/erase", as: "user", body: `{"password":"wrong"}`, status: 403},
+		{name: "stream anonymous", route: "GET /api/v1/stream", status: 401},
+
+		{name: "list reports", route: "GET /api/v1/admin/reports", as: "admin", status: 200},
+		{name: "list reports as user", route: "GET /api/v1/admin/reports", as: "user", status: 403},
+		{name: "dismiss report", route: "POST /api/v1/admin/reports/{id}/dismiss", path: "/api/v1/admin/reports/1/dismiss", as: "admin", status: 204},
+		{name: "dismiss missing report", route: "POST /api/v1/admin/reports/{id}/dismiss", path: "/api/v1/admin/reports/404/dismiss", as: "admin", status: 404},
+		{name: "hide review", route: "POST /api/v1/admin/reviews/{id}/hide", path: "/api/v1/admin/reviews/1/hide", as: "admin", status: 204},
+		{name: "restore review", route: "POST /api/v1/admin/reviews/{id}/restore", path: "/api/v1/admin/reviews/1/restore", as: "admin", status: 204},
+		{name: "undelete review", route: "POST /api/v1/admin/reviews/{id}/undelete", path: "/api/v1/admin/reviews/1/undelete", as: "admin", status: 204},
+		{name: "moderation results", route: "GET /api/v1/admin/moderation-results", as: "admin", status: 200},
+		{name: "delete user", route: "DELETE /api/v1/admin/users/{id}", path: "/api/v1/admin/users/2", as: "admin", status: 204},
+		{name: "undelete user", route: "POST /api/v1/admin/users/{id}/undelete", path: "/api/v1/admin/users/2/undelete", as: "admin", status: 204},
+		{name: "erase user", route: "POST /api/v1/admin/users/{id}/erase", path: "/api/v1/admin/users/2/erase", as: "admin", status: 204},
+		{name: "erase missing user", route: "POST /api/v1/admin/users/{id}/erase", path: "/api/v1/admin/users/404/erase", as: "admin", status: 404},
+		{name: "audit log", route: "GET /api/v1/admin/audit-log", as: "admin", status: 200},
+		{name: "list webhooks", route: "GET /api/v1/admin/webhooks", as: "admin", status: 200},
+		{name: "create webhook", route: "POST /api/v1/admin/webhooks", as: "admin", body: webhook, status: 201},
+		{name: "create webhook unknown event", route: "POST /api/v1/admin/webhooks", as: "admin", body: `{"url":"https://example.com/hook","eventTypes":["vote.cast"]}`, status: 400},
+		{name: "get webhook", route: "GET /api/v1/admin/webhooks/{id}", path: "/api/v1/admin/webhooks/1", as: "admin", status: 200},
+		{name: "get missing webhook", route: "GET /api/v1/admin/webhooks/{id}", path: "/api/v1/admin/webhooks/404", as: "admin", status: 404},
+		{name: "update webhook", route: "PATCH /api/v1/admin/webhooks/{id}", path: "/api/v1/admin/webhooks/1", as: "admin", body: `{"active":false}`, status: 200},
+		{name: "delete webhook", route: "DELETE /api/v1/admin/webhooks/{id}", path: "/api/v1/admin/webhooks/1", as: "admin", status: 204},
+		{name: "webhook deliveries", route: "GET /api/v1/admin/webhooks/{id}/deliveries", path: "/api/v1/admin/webhooks/1/deliveries", as: "admin", status: 200},
+		{name: "ping webhook", route: "POST /api/v1/admin/webhooks/{id}/ping", path: "/api/v1/admin/webhooks/1/ping", as: "admin", status: 200},
+
+		{name: "openapi document", route: "GET /api/v1/openapi.json", status: 200},
+		{name: "liveness", route: "GET /healthz", status: 200},
+		{name: "readiness", route: "GET /readyz", status: 200},
+		{name: "metrics", route: "GET /metrics", status: 200},
+		{name: "version", route: "GET /version", status: 200},
+	}
+
+	covered := make(map[string]bool)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			method, pattern, _ := strings.Cut(tc.route, " ")
+			target := tc.path
+			if target == "" {
+				target = pattern
+			}
+			req := httptest.NewRequest(method, target, strings.NewReader(tc.body))
+			switch tc.as {
+			case "user":
+				req.Header.Set("Authorization", "Bearer "+userToken)
+			case "admin":
+				req.Header.Set("Authorization", "Bearer "+adminToken)
+			}
+			for name, value := range tc.head {
+				req.Header.Set(name, value)
+			}
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tc.status, rec.Body.String())
+			}
+			for _, name := range tc.headers {
+				if rec.Header().Get(name) == "" {
+					t.Errorf("header %s is missing", name)
+				}
+			}
+			for _, violation := range spec.CheckResponse(method, pattern, rec.Code, rec.Header(), rec.Body.Bytes()) {
+				t.Errorf("response does not match openapi.json: %s", violation)
+			}
+		})
+		covered[tc.route] = true
+	}
+
+	for _, operation := range spec.Operations() {
+		if !covered[operation] && operation != "GET /api/v1/stream" {
+			t.Errorf("%s has no test case", operation)
+		}
+	}
+}
+
+// TestStreamConformsToOpenAPI covers the event stream, which never ends on
+// its own and so cannot go through a ResponseRecorder
+func TestStreamConformsToOpenAPI(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := auth.NewTokens("0123456789abcdef0123456789abcdef", time.Hour)
+	token, _ := tokens.Issue(&model.User{ID: 1, Role: model.RoleUser})
+	srv := httptest.NewServer(newTestServer(t, spec, tokens, true))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/stream", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	for _, violation := range spec.CheckResponse(http.MethodGet, "/api/v1/stream", resp.StatusCode, resp.Header, nil) {
+		t.Errorf("response does not match openapi.json: %s", violation)
+	}
+}
+
+func TestReadinessFailureConformsToOpenAPI(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, spec, auth.NewTokens("0123456789abcdef0123456789abcdef", time.Hour), false)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	for _, violation := range spec.CheckResponse(http.MethodGet, "/readyz", rec.Code, rec.Header(), rec.Body.Bytes()) {
+		t.Errorf("response does not match openapi.json: %s", violation)
+	}
+}
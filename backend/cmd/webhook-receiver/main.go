@@ -0,0 +1,64 @@
+// Command webhook-receiver is a local endpoint for trying out webhooks. It
+// checks the signature of every request and logs the event. Register it
+// with the admin API using the same secret, e.g.
+//
+//	go run ./cmd/webhook-receiver -secret 0123456789abcdef
+//	POST /api/v1/admin/webhooks {"url": "http://localhost:9000/", "secret": "0123456789abcdef", "eventTypes": ["review.created"]}
+//
+// -status makes it answer with an error to watch retries and disabling.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/webhook"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", ":9000", "Listen address")
+		secret    = flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "Webhook secret (default $WEBHOOK_SECRET)")
+		status    = flag.Int("status", http.StatusNoContent, "Status to answer valid requests with")
+		tolerance = flag.Duration("tolerance", 5*time.Minute, "Accepted clock difference of the signature timestamp")
+	)
+	flag.Parse()
+	slog.SetDefault(logging.New(os.Stderr, slog.LevelInfo))
+
+	if *secret == "" {
+		slog.Error("a secret is required; pass -secret or set WEBHOOK_SECRET")
+		os.Exit(2)
+	}
+
+	http.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if err := webhook.Verify(*secret, r.Header, body, *tolerance); err != nil {
+			slog.Warn("rejected webhook", "error", err, "event_id", r.Header.Get(webhook.HeaderID))
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		slog.Info("received webhook",
+			"event_id", r.Header.Get(webhook.HeaderID),
+			"event_type", r.Header.Get(webhook.HeaderEvent),
+			"body", json.RawMessage(body),
+		)
+		w.WriteHeader(*status)
+	})
+
+	slog.Info("listening for webhooks", "addr", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		slog.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
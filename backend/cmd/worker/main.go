@@ -0,0 +1,70 @@
+// Command worker processes background jobs without serving HTTP. Run it
+// with JOBS_RUN_IN_SERVER=false on the API servers to keep slow jobs off
+// the instances that answer requests; any number of workers can run.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/factory"
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/tracing"
+)
+
+func main() {
+	slog.SetDefault(logging.New(os.Stderr, slog.LevelInfo))
+	cfg := config.MustLoad(".env")
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	slog.SetDefault(logging.New(os.Stderr, level))
+
+	cfg.Tracing.ServiceName += "-worker"
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+
+	cluster, err := db.OpenCluster(context.Background(), cfg.DB)
+	if err != nil {
+		fatal("failed to connect to database", err)
+	}
+
+	appFactory := factory.New(cluster, cfg)
+	repos, services, _ := appFactory.NewAppComponents()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	stopWorker := appFactory.NewJobWorker(repos, services).Start(context.Background())
+	<-ctx.Done()
+	// A second signal kills the process immediately
+	stop()
+
+	slog.Info("shutting down, waiting for running jobs", "timeout", cfg.Jobs.ShutdownTimeout)
+	if !stopWorker(cfg.Jobs.ShutdownTimeout) {
+		slog.Warn("jobs still running at shutdown; they are retried after their lease expires")
+	}
+
+	appFactory.Close()
+	if err := appFactory.Cache.Close(); err != nil {
+		slog.Error("failed to close cache", "error", err)
+	}
+	if err := cluster.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	cancel()
+	slog.Info("worker stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,71 @@
+// Package auth issues and verifies the JWTs used for API authentication.
+// Keeping both sides here guarantees they use the same secret.
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"protein-web-backend/internal/model"
+)
+
+var ErrInvalidToken = errors.New("Invalid token")
+
+// Claims are the values the API reads back from a verified token
+type Claims struct {
+	UserID int
+	Role   string
+}
+
+type Tokens struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewTokens(secret string, ttl time.Duration) *Tokens {
+	return &Tokens{secret: []byte(secret), ttl: ttl}
+}
+
+// Issue signs a token for user that expires after the configured TTL
+func (t *Tokens) Issue(user *model.User) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"name":    user.Name,
+		"role":    user.Role,
+		"exp":     now.Add(t.ttl).Unix(),
+		"iat":     now.Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+}
+
+// Parse verifies the signature and expiry of tokenString
+func (t *Tokens) Parse(tokenString string) (*Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return t.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("Invalid token claims")
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, errors.New("Invalid user ID in token")
+	}
+	role, _ := claims["role"].(string)
+
+	return &Claims{UserID: int(userID), Role: role}, nil
+}
@@ -0,0 +1,32 @@
+// Package cache stores serialized values for a limited time. Memory keeps
+// them in the process; Redis shares them between instances through any
+// server that speaks the Redis protocol. Loader puts a cache in front of a
+// slower source and collapses concurrent misses into one load.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrClosed is returned by operations on a closed cache
+var ErrClosed = errors.New("cache: closed")
+
+// Cache is safe for concurrent use. A ttl of 0 stores the value until it is
+// deleted or evicted.
+type Cache interface {
+	// Get reports false when the key is missing or has expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+// Nop never stores anything, so every Get is a miss
+type Nop struct{}
+
+func (Nop) Get(context.Context, string) ([]byte, bool, error)        { return nil, false, nil }
+func (Nop) Set(context.Context, string, []byte, time.Duration) error { return nil }
+func (Nop) Delete(context.Context, ...string) error                  { return nil }
+func (Nop) Close() error                                             { return nil }
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"protein-web-backend/internal/logging"
+)
+
+// Loader reads through a cache. When a key is missing, one caller loads it
+// and the others asking for the same key meanwhile wait for that result
+// instead of hitting the source too (stampede protection). Only callers in
+// this process are coalesced.
+//
+// Cache errors are logged and treated as misses: a cache outage makes
+// requests slower, not fail.
+type Loader struct {
+	cache Cache
+
+	mu       sync.Mutex
+	inflight map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+func NewLoader(c Cache) *Loader {
+	return &Loader{cache: c, inflight: make(map[string]*call)}
+}
+
+// Cache returns the cache the loader reads from
+func (l *Loader) Cache() Cache {
+	return l.cache
+}
+
+// Load returns the cached value for key, or calls load and caches its
+// result for ttl. Errors from load, and panics as errors, are returned to
+// every waiting caller and not cached.
+func (l *Loader) Load(ctx context.Context, key string, ttl time.Duration, load func(context.Context) ([]byte, error)) ([]byte, error) {
+	value, ok, err := l.cache.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("cache read failed", "key", key, "error", err)
+	}
+	if ok {
+		return value, nil
+	}
+
+	l.mu.Lock()
+	c, running := l.inflight[key]
+	if !running {
+		c = &call{done: make(chan struct{})}
+		l.inflight[key] = c
+	}
+	l.mu.Unlock()
+
+	if !running {
+		loadCtx, cancel := detach(ctx)
+		go func() {
+			defer cancel()
+			l.run(loadCtx, key, ttl, load, c)
+		}()
+	}
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Loader) run(ctx context.Context, key string, ttl time.Duration, load func(context.Context) ([]byte, error), c *call) {
+	defer func() {
+		l.mu.Lock()
+		delete(l.inflight, key)
+		l.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.value, c.err = callLoad(ctx, load)
+	if c.err != nil {
+		return
+	}
+	if err := l.cache.Set(ctx, key, c.value, ttl); err != nil {
+		logging.FromContext(ctx).Warn("cache write failed", "key", key, "error", err)
+	}
+}
+
+// callLoad turns a panic in load into an error. The load runs on its own
+// goroutine, where a panic would otherwise crash the process and leave the
+// waiters and the in-flight entry behind.
+func callLoad(ctx context.Context, load func(context.Context) ([]byte, error)) (value []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			value, err = nil, fmt.Errorf("cache loader panicked: %v", p)
+		}
+	}()
+	return load(ctx)
+}
+
+// detach keeps the values and deadline of the first caller's context but not
+// its cancellation, so a client that disconnects does not fail the others
+// waiting on the same load.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
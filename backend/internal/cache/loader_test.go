@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingCache records how many reads missed
+type countingCache struct {
+	Cache
+	misses sync.WaitGroup
+}
+
+func (c *countingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Cache.Get(ctx, key)
+	if !ok {
+		c.misses.Done()
+	}
+	return value, ok, err
+}
+
+func TestLoaderLoad(t *testing.T) {
+	errSource := errors.New("source down")
+
+	tests := []struct {
+		name    string
+		cached  string
+		load    func(context.Context) ([]byte, error)
+		want    string
+		wantErr string
+		stored  bool
+	}{
+		{
+			name:   "a hit does not load",
+			cached: "cached",
+			load:   func(context.Context) ([]byte, error) { return nil, errors.New("loaded") },
+			want:   "cached",
+			stored: true,
+		},
+		{
+			name:   "a miss loads and stores",
+			load:   func(context.Context) ([]byte, error) { return []byte("loaded"), nil },
+			want:   "loaded",
+			stored: true,
+		},
+		{
+			name:    "errors are returned and not stored",
+			load:    func(context.Context) ([]byte, error) { return nil, errSource },
+			wantErr: errSource.Error(),
+		},
+		{
+			name:    "a panic becomes an error",
+			load:    func(context.Context) ([]byte, error) { panic("boom") },
+			wantErr: "cache loader panicked: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := NewMemory(10)
+			if tt.cached != "" {
+				c.Set(ctx, "key", []byte(tt.cached), 0)
+			}
+			l := NewLoader(c)
+
+			got, err := l.Load(ctx, "key", time.Minute, tt.load)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || string(got) != tt.want {
+				t.Fatalf("Load = %q, %v, want %q", got, err, tt.want)
+			}
+
+			if _, ok, _ := c.Get(ctx, "key"); ok != tt.stored {
+				t.Errorf("stored = %v, want %v", ok, tt.stored)
+			}
+			if len(l.inflight) != 0 {
+				t.Errorf("in-flight entries left behind: %v", l.inflight)
+			}
+		})
+	}
+}
+
+func TestLoaderLoadsOnceForConcurrentCallers(t *testing.T) {
+	const callers = 50
+	c := &countingCache{Cache: NewMemory(10)}
+	c.misses.Add(callers)
+	l := NewLoader(c)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) ([]byte, error) {
+		loads.Add(1)
+		<-release
+		return []byte("value"), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := l.Load(context.Background(), "key", time.Minute, load)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- string(value)
+		}()
+	}
+
+	// Every caller has missed; give them a moment to join the load
+	c.misses.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := loads.Load(); got != 1 {
+		t.Errorf("loaded %d times, want 1", got)
+	}
+	for value := range results {
+		if value != "value" {
+			t.Errorf("caller got %q, want %q", value, "value")
+		}
+	}
+}
+
+func TestLoaderPanicReachesWaiters(t *testing.T) {
+	const callers = 10
+	c := &countingCache{Cache: NewMemory(10)}
+	c.misses.Add(callers)
+	l := NewLoader(c)
+
+	release := make(chan struct{})
+	load := func(context.Context) ([]byte, error) {
+		<-release
+		panic("boom")
+	}
+
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Load(context.Background(), "key", time.Minute, load); err == nil {
+				t.Error("waiter got no error from a panicking load")
+			}
+		}()
+	}
+	c.misses.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// The key is free again for the next load
+	c.misses.Add(1)
+	value, err := l.Load(context.Background(), "key", time.Minute, func(context.Context) ([]byte, error) {
+		return []byte("value"), nil
+	})
+	if err != nil || string(value) != "value" {
+		t.Fatalf("Load after panic = %q, %v", value, err)
+	}
+}
+
+func TestLoaderSurvivesCancelledCaller(t *testing.T) {
+	l := NewLoader(NewMemory(10))
+	release := make(chan struct{})
+	load := func(ctx context.Context) ([]byte, error) {
+		<-release
+		return []byte("value"), ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := l.Load(ctx, "key", time.Minute, load)
+		first <- err
+	}()
+	for {
+		l.mu.Lock()
+		_, running := l.inflight["key"]
+		l.mu.Unlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan string)
+	go func() {
+		value, _ := l.Load(context.Background(), "key", time.Minute, load)
+		second <- string(value)
+	}()
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller err = %v", err)
+	}
+	close(release)
+	if value := <-second; value != "value" {
+		t.Errorf("other caller got %q, want %q", value, "value")
+	}
+}
@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RedisOptions struct {
+	// Addr is host:port of a Redis, Valkey or other RESP-compatible server
+	Addr     string
+	Password string
+	DB       int
+	// PoolSize is the number of idle connections kept for reuse. Busy
+	// periods may open more; the extra ones are closed when returned.
+	PoolSize int
+	// Timeout bounds dialing and each command unless the context ends sooner
+	Timeout time.Duration
+}
+
+// Redis is a minimal client for the Redis serialization protocol (RESP2).
+// It implements Cache with GET, SET and DEL; Do sends other commands.
+// Connections are dialed on first use, so the server does not have to be up
+// when the process starts.
+type Redis struct {
+	opts RedisOptions
+	idle chan *redisConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// RedisError is an error reply from the server. The connection stays usable.
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+type redisConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func NewRedis(opts RedisOptions) *Redis {
+	return &Redis{opts: opts, idle: make(chan *redisConn, opts.PoolSize)}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	switch v := reply.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return v, true, nil
+	default:
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []interface{}{"SET", key, value}
+	if ttl > 0 {
+		// PX has millisecond resolution; never round a short TTL down to 0
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := r.Do(ctx, args...)
+	return err
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	_, err := r.Do(ctx, args...)
+	return err
+}
+
+// Close closes the idle connections. Connections in use are closed when
+// their command finishes.
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	close(r.idle)
+	for c := range r.idle {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// Do sends one command and reads its reply: nil, []byte, int64, string or
+// []interface{}. Arguments are strings or []byte. A connection that failed
+// mid-command is discarded, since the reply stream may be out of step.
+func (r *Redis) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	c, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(r.opts.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	reply, err := c.roundTrip(args)
+	var serverErr RedisError
+	if err != nil && !errors.As(err, &serverErr) {
+		c.conn.Close()
+		return nil, fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	r.put(c)
+	return reply, err
+}
+
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, ErrClosed
+	}
+	select {
+	case c := <-r.idle:
+		r.mu.Unlock()
+		return c, nil
+	default:
+	}
+	r.mu.Unlock()
+	return r.dial(ctx)
+}
+
+func (r *Redis) put(c *redisConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		c.conn.Close()
+		return
+	}
+	select {
+	case r.idle <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+func (r *Redis) dial(ctx context.Context) (*redisConn, error) {
+	dialer := net.Dialer{Timeout: r.opts.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	c := &redisConn{conn: conn, rw: bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))}
+
+	conn.SetDeadline(time.Now().Add(r.opts.Timeout))
+	if r.opts.Password != "" {
+		if _, err := c.roundTrip([]interface{}{"AUTH", r.opts.Password}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis AUTH: %w", err)
+		}
+	}
+	if r.opts.DB != 0 {
+		if _, err := c.roundTrip([]interface{}{"SELECT", strconv.Itoa(r.opts.DB)}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis SELECT: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) roundTrip(args []interface{}) (interface{}, error) {
+	if err := writeCommand(c.rw.Writer, args); err != nil {
+		return nil, err
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(c.rw.Reader)
+}
+
+// writeCommand encodes args as an array of bulk strings
+func writeCommand(w *bufio.Writer, args []interface{}) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			return fmt.Errorf("unsupported argument type %T", arg)
+		}
+		fmt.Fprintf(w, "$%d\r\n", len(b))
+		w.Write(b)
+		w.WriteString("\r\n")
+	}
+	return nil
+}
+
+// readReply decodes one reply: a simple string (string), error (RedisError),
+// integer (int64), bulk string ([]byte), array ([]interface{}) or null (nil)
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], string(line[1:len(line)-2])
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, RedisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed array length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				var serverErr RedisError
+				if !errors.As(err, &serverErr) {
+					return nil, err
+				}
+				items[i] = serverErr
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", kind)
+	}
+}
@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "strings",
+			args: []interface{}{"GET", "key"},
+			want: "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n",
+		},
+		{
+			name: "binary values keep CRLF and are counted in bytes",
+			args: []interface{}{"SET", "k", []byte("a\r\nß")},
+			want: "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$5\r\na\r\nß\r\n",
+		},
+		{
+			name: "empty argument",
+			args: []interface{}{"SET", "k", []byte{}},
+			want: "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$0\r\n\r\n",
+		},
+		{
+			name:    "unsupported argument",
+			args:    []interface{}{"EXPIRE", "k", 10},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			err := writeCommand(w, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			w.Flush()
+			if buf.String() != tt.want {
+				t.Errorf("wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "simple string", in: "+OK\r\n", want: "OK"},
+		{name: "error", in: "-ERR wrong type\r\n", wantErr: "redis: ERR wrong type"},
+		{name: "integer", in: ":-42\r\n", want: int64(-42)},
+		{name: "bulk string", in: "$5\r\nhello\r\n", want: []byte("hello")},
+		{name: "bulk string with CRLF", in: "$4\r\na\r\nb\r\n", want: []byte("a\r\nb")},
+		{name: "empty bulk string", in: "$0\r\n\r\n", want: []byte{}},
+		{name: "null bulk string", in: "$-1\r\n", want: nil},
+		{name: "null array", in: "*-1\r\n", want: nil},
+		{
+			name: "array",
+			in:   "*3\r\n:1\r\n$1\r\na\r\n*1\r\n+OK\r\n",
+			want: []interface{}{int64(1), []byte("a"), []interface{}{"OK"}},
+		},
+		{
+			name: "errors inside an array are values",
+			in:   "*2\r\n+OK\r\n-ERR no\r\n",
+			want: []interface{}{"OK", RedisError("ERR no")},
+		},
+		{name: "missing CR", in: "+OK\n", wantErr: "malformed reply"},
+		{name: "bad bulk length", in: "$x\r\n", wantErr: "malformed bulk length"},
+		{name: "bad array length", in: "*x\r\n", wantErr: "malformed array length"},
+		{name: "unknown type", in: "?1\r\n", wantErr: "unknown reply type"},
+		{name: "truncated bulk string", in: "$5\r\nhel", wantErr: "unexpected EOF"},
+		{name: "truncated array", in: "*2\r\n:1\r\n", wantErr: "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.in)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRedis serves GET, SET, DEL, AUTH and SELECT from a map. Commands are
+// decoded with readReply, since a command is an array of bulk strings.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string][]byte
+	commands [][]string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{listener: l, password: password, data: make(map[string][]byte)}
+	go f.serve()
+	t.Cleanup(func() { l.Close() })
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, string(arg.([]byte)))
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, args)
+		var out string
+		switch {
+		case args[0] == "AUTH":
+			authed = args[1] == f.password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			out = "+OK\r\n"
+		case args[0] == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				out = "$" + strconv.Itoa(len(v)) + "\r\n" + string(v) + "\r\n"
+			} else {
+				out = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			f.data[args[1]] = []byte(args[2])
+			out = "+OK\r\n"
+		case args[0] == "DEL":
+			n := 0
+			for _, key := range args[1:] {
+				if _, ok := f.data[key]; ok {
+					delete(f.data, key)
+					n++
+				}
+			}
+			out = ":" + strconv.Itoa(n) + "\r\n"
+		default:
+			out = "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) sent() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.commands...)
+}
+
+func TestRedisCache(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	r := NewRedis(RedisOptions{Addr: f.listener.Addr().String(), Password: "secret", DB: 2, PoolSize: 1, Timeout: time.Second})
+	defer r.Close()
+	ctx := context.Background()
+
+	if _, ok, err := r.Get(ctx, "key"); ok || err != nil {
+		t.Fatalf("Get of a missing key = %v, %v", ok, err)
+	}
+	if err := r.Set(ctx, "key", []byte("a\r\nb"), 1500*time.Microsecond); err != nil {
+		t.Fatal(err)
+	}
+	value, ok, err := r.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "a\r\nb" {
+		t.Fatalf("Get = %q, %v, %v", value, ok, err)
+	}
+	if err := r.Delete(ctx, "key", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := r.Get(ctx, "key"); ok {
+		t.Fatal("key still present after Delete")
+	}
+
+	// An error reply leaves the connection in the pool: AUTH is sent once
+	if _, err := r.Do(ctx, "PING"); !errors.As(err, new(RedisError)) {
+		t.Fatalf("Do(PING) err = %v, want a RedisError", err)
+	}
+	if _, _, err := r.Get(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"AUTH", "secret"},
+		{"SELECT", "2"},
+		{"GET", "key"},
+		{"SET", "key", "a\r\nb", "PX", "1"},
+		{"GET", "key"},
+		{"DEL", "key", "other"},
+		{"GET", "key"},
+		{"PING"},
+		{"GET", "key"},
+	}
+	if got := f.sent(); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %q\nwant %q", got, want)
+	}
+}
+
+func TestRedisWrongPassword(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	r := NewRedis(RedisOptions{Addr: f.listener.Addr().String(), Password: "wrong", PoolSize: 1, Timeout: time.Second})
+	defer r.Close()
+
+	_, _, err := r.Get(context.Background(), "key")
+	if err == nil || !strings.Contains(err.Error(), "redis AUTH") {
+		t.Fatalf("err = %v, want an AUTH error", err)
+	}
+}
+
+func TestRedisClosed(t *testing.T) {
+	r := NewRedis(RedisOptions{Addr: "127.0.0.1:1", PoolSize: 1, Timeout: time.Second})
+	r.Close()
+	if _, _, err := r.Get(context.Background(), "key"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("err = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,633 @@
+// Package config loads the application settings into one typed struct.
+//
+// Values are layered, later sources winning: built-in defaults, an optional
+// YAML file (CONFIG_FILE), then environment variables, which include the
+// contents of .env. MustLoad validates the result so that misconfiguration
+// stops the process at startup instead of surfacing on the first request.
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/moderation"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// minJWTSecretLength is the shortest secret accepted in production. HS256
+// keys shorter than the 32-byte hash output weaken the signature.
+const minJWTSecretLength = 32
+
+// weakJWTSecrets are placeholder values that must never sign real tokens
+var weakJWTSecrets = []string{"your-secret-key", "secret", "changeme", "change-me", "jwt-secret"}
+
+type Config struct {
+	Env         string            `yaml:"env" env:"APP_ENV"`
+	HTTP        HTTPConfig        `yaml:"http"`
+	DB          DBConfig          `yaml:"db"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Moderation  ModerationConfig  `yaml:"moderation"`
+	Reports     ReportsConfig     `yaml:"reports"`
+	Privacy     PrivacyConfig     `yaml:"privacy"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Jobs        JobsConfig        `yaml:"jobs"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+type HTTPConfig struct {
+	Addr           string        `yaml:"addr" env:"HTTP_ADDR"`
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT"`
+	// Server-side connection limits, see http.Server
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	// ShutdownTimeout is how long in-flight requests may take to finish after
+	// SIGINT or SIGTERM before their connections are closed
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	// UnversionedSunset is when the deprecated /api/... aliases go away
+	UnversionedSunset time.Time `yaml:"unversioned_sunset" env:"API_UNVERSIONED_SUNSET"`
+	// ValidateResponses checks responses against openapi.json (development aid)
+	ValidateResponses bool `yaml:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES"`
+}
+
+type DBConfig struct {
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	// PingTimeout bounds the database checks of the readiness probe
+	PingTimeout time.Duration `yaml:"ping_timeout" env:"DB_PING_TIMEOUT"`
+
+	// Connection pool, see sql.DB. MaxOpenConns 0 means unlimited.
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// ConnectTimeout is how long startup keeps retrying while the database
+	// is not reachable yet, e.g. during docker compose up
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+
+	// ReplicaHost enables read replica routing for feed queries. The
+	// replica uses the same credentials, database name and pool settings.
+	ReplicaHost string `yaml:"replica_host" env:"DB_REPLICA_HOST"`
+	ReplicaPort string `yaml:"replica_port" env:"DB_REPLICA_PORT"`
+	// ReplicaStickiness keeps a user's reads on the primary for this long
+	// after they write, so they see their own changes despite replication lag
+	ReplicaStickiness    time.Duration `yaml:"replica_stickiness" env:"DB_REPLICA_STICKINESS"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL"`
+}
+
+// Replica returns the settings for connecting to the read replica, or false
+// when none is configured.
+func (c DBConfig) Replica() (DBConfig, bool) {
+	if c.ReplicaHost == "" {
+		return DBConfig{}, false
+	}
+	replica := c
+	replica.Host = c.ReplicaHost
+	if c.ReplicaPort != "" {
+		replica.Port = c.ReplicaPort
+	}
+	return replica, true
+}
+
+// Validate reports missing connection settings. Commands that only need the
+// database, such as migrate, validate this section alone.
+func (c DBConfig) Validate() error {
+	var errs []error
+	for _, setting := range []struct{ name, value string }{
+		{"DB_USER", c.User},
+		{"DB_HOST", c.Host},
+		{"DB_PORT", c.Port},
+		{"DB_NAME", c.Name},
+	} {
+		if setting.value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", setting.name))
+		}
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS"))
+	}
+	if c.ConnMaxLifetime < 0 || c.ConnMaxIdleTime < 0 {
+		errs = append(errs, errors.New("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative"))
+	}
+	if c.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("DB_CONNECT_TIMEOUT must be positive"))
+	}
+	if c.ReplicaHost != "" && (c.ReplicaStickiness < 0 || c.ReplicaCheckInterval <= 0) {
+		errs = append(errs, errors.New("DB_REPLICA_STICKINESS must not be negative and DB_REPLICA_CHECK_INTERVAL must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+type AuthConfig struct {
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET"`
+	TokenTTL  time.Duration `yaml:"token_ttl" env:"JWT_TTL"`
+}
+
+type ModerationConfig struct {
+	BannedWordsFile   string   `yaml:"banned_words_file" env:"MODERATION_BANNED_WORDS_FILE"`
+	BannedWordsAction string   `yaml:"banned_words_action" env:"MODERATION_BANNED_WORDS_ACTION"`
+	AllowedDomains    []string `yaml:"allowed_domains" env:"MODERATION_ALLOWED_DOMAINS"`
+	LinksAction       string   `yaml:"links_action" env:"MODERATION_LINKS_ACTION"`
+	PhoneAction       string   `yaml:"phone_action" env:"MODERATION_PHONE_ACTION"`
+	SpamAction        string   `yaml:"spam_action" env:"MODERATION_SPAM_ACTION"`
+}
+
+type ReportsConfig struct {
+	// HideThreshold is the number of open reports that hides a review; 0 disables it
+	HideThreshold int `yaml:"hide_threshold" env:"REPORT_HIDE_THRESHOLD"`
+}
+
+type PrivacyConfig struct {
+	// ErasureReviewPolicy is "delete" or "anonymize"
+	ErasureReviewPolicy string `yaml:"erasure_review_policy" env:"ERASURE_REVIEW_POLICY"`
+	UploadDir           string `yaml:"upload_dir" env:"UPLOAD_DIR"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled" env:"OTEL_TRACING_ENABLED"`
+	// Endpoint is the OTLP/HTTP collector URL; http:// disables TLS
+	Endpoint    string `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	// SampleRatio is the fraction of traces recorded, from 0 to 1. It also
+	// applies to requests that arrive with a traceparent header.
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLE_RATIO"`
+}
+
+// Cache backends
+const (
+	CacheNone   = "none"
+	CacheMemory = "memory"
+	CacheRedis  = "redis"
+)
+
+type CacheConfig struct {
+	// Backend is none, memory or redis. The memory cache is per process, so
+	// other instances keep serving invalidated entries until they expire;
+	// run several instances with redis.
+	Backend string        `yaml:"backend" env:"CACHE_BACKEND"`
+	TTL     time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	// MaxEntries bounds the memory cache; the least recently used entries
+	// are evicted first
+	MaxEntries int `yaml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+
+	RedisAddr     string        `yaml:"redis_addr" env:"REDIS_ADDR"`
+	RedisPassword string        `yaml:"redis_password" env:"REDIS_PASSWORD"`
+	RedisDB       int           `yaml:"redis_db" env:"REDIS_DB"`
+	RedisPoolSize int           `yaml:"redis_pool_size" env:"REDIS_POOL_SIZE"`
+	RedisTimeout  time.Duration `yaml:"redis_timeout" env:"REDIS_TIMEOUT"`
+}
+
+type JobsConfig struct {
+	// RunInServer starts a worker pool inside the API server. Turn it off
+	// when cmd/worker processes the jobs instead.
+	RunInServer  bool          `yaml:"run_in_server" env:"JOBS_RUN_IN_SERVER"`
+	Concurrency  int           `yaml:"concurrency" env:"JOBS_CONCURRENCY"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"JOBS_POLL_INTERVAL"`
+	// Lease is how long a job may run before another worker may take it over
+	Lease       time.Duration `yaml:"lease" env:"JOBS_LEASE"`
+	MaxAttempts int           `yaml:"max_attempts" env:"JOBS_MAX_ATTEMPTS"`
+	BackoffBase time.Duration `yaml:"backoff_base" env:"JOBS_BACKOFF_BASE"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env:"JOBS_BACKOFF_MAX"`
+	// Retention is how long completed jobs are kept. Dead jobs stay until
+	// they are deleted by hand.
+	Retention time.Duration `yaml:"retention" env:"JOBS_RETENTION"`
+	// ShutdownTimeout is how long shutdown waits for running jobs. Jobs
+	// still running afterwards are retried once their lease expires.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"JOBS_SHUTDOWN_TIMEOUT"`
+}
+
+// Validate reports invalid worker settings
+func (c JobsConfig) Validate() error {
+	var errs []error
+	if c.Concurrency <= 0 {
+		errs = append(errs, errors.New("JOBS_CONCURRENCY must be positive"))
+	}
+	if c.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("JOBS_MAX_ATTEMPTS must be positive"))
+	}
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"JOBS_POLL_INTERVAL", c.PollInterval},
+		{"JOBS_LEASE", c.Lease},
+		{"JOBS_BACKOFF_BASE", c.BackoffBase},
+		{"JOBS_BACKOFF_MAX", c.BackoffMax},
+		{"JOBS_RETENTION", c.Retention},
+		{"JOBS_SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+	} {
+		if setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", setting.name))
+		}
+	}
+	if c.BackoffMax < c.BackoffBase {
+		errs = append(errs, errors.New("JOBS_BACKOFF_MAX must not be shorter than JOBS_BACKOFF_BASE"))
+	}
+	return errors.Join(errs...)
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	// Lease is how long a relay may take to publish a batch before another
+	// relay may publish its events again
+	Lease       time.Duration `yaml:"lease" env:"OUTBOX_LEASE"`
+	MaxAttempts int           `yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	BackoffBase time.Duration `yaml:"backoff_base" env:"OUTBOX_BACKOFF_BASE"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env:"OUTBOX_BACKOFF_MAX"`
+	// Retention is how long published events and consumer records are kept.
+	// Dead events stay until they are deleted by hand.
+	Retention       time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"OUTBOX_SHUTDOWN_TIMEOUT"`
+
+	// WebhookURL receives every event as a JSON POST when set
+	WebhookURL     string        `yaml:"webhook_url" env:"OUTBOX_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" env:"OUTBOX_WEBHOOK_TIMEOUT"`
+	// Stream names a Redis stream that receives every event when set. The
+	// server is reached with the REDIS_* settings.
+	Stream       string `yaml:"stream" env:"OUTBOX_STREAM"`
+	StreamMaxLen int    `yaml:"stream_max_len" env:"OUTBOX_STREAM_MAX_LEN"`
+}
+
+// Validate reports invalid relay settings
+func (c OutboxConfig) Validate() error {
+	var errs []error
+	if c.BatchSize <= 0 {
+		errs = append(errs, errors.New("OUTBOX_BATCH_SIZE must be positive"))
+	}
+	if c.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("OUTBOX_MAX_ATTEMPTS must be positive"))
+	}
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"OUTBOX_POLL_INTERVAL", c.PollInterval},
+		{"OUTBOX_LEASE", c.Lease},
+		{"OUTBOX_BACKOFF_BASE", c.BackoffBase},
+		{"OUTBOX_BACKOFF_MAX", c.BackoffMax},
+		{"OUTBOX_RETENTION", c.Retention},
+		{"OUTBOX_SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+	} {
+		if setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", setting.name))
+		}
+	}
+	if c.BackoffMax < c.BackoffBase {
+		errs = append(errs, errors.New("OUTBOX_BACKOFF_MAX must not be shorter than OUTBOX_BACKOFF_BASE"))
+	}
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OUTBOX_WEBHOOK_URL must be an http(s) URL, got %q", c.WebhookURL))
+		}
+		if c.WebhookTimeout <= 0 {
+			errs = append(errs, errors.New("OUTBOX_WEBHOOK_TIMEOUT must be positive"))
+		}
+	}
+	if c.StreamMaxLen < 0 {
+		errs = append(errs, errors.New("OUTBOX_STREAM_MAX_LEN must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+type WebhooksConfig struct {
+	// Timeout bounds each delivery, including reading the response
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT"`
+	// DisableAfter is the number of consecutive failed deliveries after
+	// which a webhook is disabled until an admin re-enables it
+	DisableAfter int `yaml:"disable_after" env:"WEBHOOKS_DISABLE_AFTER"`
+	// DeliveryRetention is how long the delivery log is kept
+	DeliveryRetention time.Duration `yaml:"delivery_retention" env:"WEBHOOKS_DELIVERY_RETENTION"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a key and its response are kept for retries
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+	// LockTimeout is how long a request that never finished holds its key
+	// before a retry may run it again
+	LockTimeout time.Duration `yaml:"lock_timeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+}
+
+// Default returns the settings used when nothing overrides them
+func Default() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			RequestTimeout:    10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   20 * time.Second,
+			// Six months after the aliases were deprecated
+			UnversionedSunset: time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		},
+		DB: DBConfig{
+			PingTimeout: 2 * time.Second,
+			// MySQL closes idle connections after wait_timeout (8h by
+			// default); recycling well before that avoids broken conns
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnMaxIdleTime: time.Minute,
+			ConnectTimeout:  60 * time.Second,
+			// Covers typical asynchronous replication lag with headroom
+			ReplicaStickiness:    5 * time.Second,
+			ReplicaCheckInterval: 5 * time.Second,
+		},
+		Auth: AuthConfig{TokenTTL: 24 * time.Hour},
+		Moderation: ModerationConfig{
+			BannedWordsAction: moderation.ActionReject.String(),
+			LinksAction:       moderation.ActionMask.String(),
+			PhoneAction:       moderation.ActionMask.String(),
+			SpamAction:        moderation.ActionFlag.String(),
+		},
+		Reports: ReportsConfig{HideThreshold: 3},
+		Privacy: PrivacyConfig{ErasureReviewPolicy: "anonymize", UploadDir: "uploads"},
+		Log:     LogConfig{Level: "info"},
+		Tracing: TracingConfig{
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "protein-web-backend",
+			SampleRatio: 1,
+		},
+		Cache: CacheConfig{
+			Backend:       CacheMemory,
+			TTL:           30 * time.Second,
+			MaxEntries:    10000,
+			RedisAddr:     "localhost:6379",
+			RedisPoolSize: 10,
+			RedisTimeout:  500 * time.Millisecond,
+		},
+		Jobs: JobsConfig{
+			RunInServer:     true,
+			Concurrency:     4,
+			PollInterval:    time.Second,
+			Lease:           5 * time.Minute,
+			MaxAttempts:     8,
+			BackoffBase:     10 * time.Second,
+			BackoffMax:      time.Hour,
+			Retention:       7 * 24 * time.Hour,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Outbox: OutboxConfig{
+			PollInterval:    500 * time.Millisecond,
+			BatchSize:       100,
+			Lease:           30 * time.Second,
+			MaxAttempts:     20,
+			BackoffBase:     time.Second,
+			BackoffMax:      5 * time.Minute,
+			Retention:       7 * 24 * time.Hour,
+			ShutdownTimeout: 10 * time.Second,
+			WebhookTimeout:  5 * time.Second,
+			StreamMaxLen:    100000,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:           10 * time.Second,
+			DisableAfter:      20,
+			DeliveryRetention: 30 * 24 * time.Hour,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         24 * time.Hour,
+			LockTimeout: time.Minute,
+		},
+	}
+}
+
+// Load reads envFile (if it exists), the YAML file named by CONFIG_FILE and
+// the environment. The result is not validated; call Validate, or
+// DB.Validate for database-only tools.
+func Load(envFile string) (*Config, error) {
+	if err := godotenv.Load(envFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load %s: %w", envFile, err)
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadYAML(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// MustLoad loads and validates the full configuration. In development a
+// missing JWT secret is replaced by a random one so the server still starts;
+// tokens then stop working on restart.
+func MustLoad(envFile string) *Config {
+	cfg, err := Load(envFile)
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	if cfg.Auth.JWTSecret == "" && cfg.Env != EnvProduction {
+		cfg.Auth.JWTSecret = randomSecret()
+		slog.Warn("JWT_SECRET is not set; using a random secret, tokens will not survive a restart")
+	}
+	if err := cfg.Validate(); err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+func (c *Config) loadYAML(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate returns every problem found, one per line
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		errs = append(errs, fmt.Errorf("APP_ENV must be %s or %s, got %q", EnvDevelopment, EnvProduction, c.Env))
+	}
+	if c.HTTP.Addr == "" {
+		errs = append(errs, errors.New("HTTP_ADDR is required"))
+	}
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"REQUEST_TIMEOUT", c.HTTP.RequestTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", c.HTTP.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.HTTP.IdleTimeout},
+		{"HTTP_SHUTDOWN_TIMEOUT", c.HTTP.ShutdownTimeout},
+		{"DB_PING_TIMEOUT", c.DB.PingTimeout},
+	} {
+		if setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", setting.name))
+		}
+	}
+	if c.HTTP.WriteTimeout > 0 && c.HTTP.WriteTimeout < c.HTTP.RequestTimeout {
+		errs = append(errs, errors.New("HTTP_WRITE_TIMEOUT must not be shorter than REQUEST_TIMEOUT"))
+	}
+	if c.HTTP.UnversionedSunset.IsZero() {
+		errs = append(errs, errors.New("API_UNVERSIONED_SUNSET must be set"))
+	}
+	if c.HTTP.MaxHeaderBytes <= 0 {
+		errs = append(errs, errors.New("HTTP_MAX_HEADER_BYTES must be positive"))
+	}
+	if err := c.DB.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := c.validateJWTSecret(); err != nil {
+		if c.Env == EnvProduction {
+			errs = append(errs, err)
+		} else {
+			slog.Warn("weak JWT secret", "error", err)
+		}
+	}
+	if c.Auth.TokenTTL <= 0 {
+		errs = append(errs, errors.New("JWT_TTL must be positive"))
+	}
+
+	for _, setting := range []struct{ name, value string }{
+		{"MODERATION_BANNED_WORDS_ACTION", c.Moderation.BannedWordsAction},
+		{"MODERATION_LINKS_ACTION", c.Moderation.LinksAction},
+		{"MODERATION_PHONE_ACTION", c.Moderation.PhoneAction},
+		{"MODERATION_SPAM_ACTION", c.Moderation.SpamAction},
+	} {
+		if _, ok := moderation.ParseAction(setting.value); !ok {
+			errs = append(errs, fmt.Errorf("%s must be one of allow, mask, flag, reject, got %q", setting.name, setting.value))
+		}
+	}
+	// A list that cannot be read would let every banned word through
+	if path := c.Moderation.BannedWordsFile; path != "" {
+		if _, err := moderation.LoadWordList(path); err != nil {
+			errs = append(errs, fmt.Errorf("MODERATION_BANNED_WORDS_FILE cannot be read: %w", err))
+		}
+	}
+
+	if c.Reports.HideThreshold < 0 {
+		errs = append(errs, errors.New("REPORT_HIDE_THRESHOLD must not be negative"))
+	}
+	if p := c.Privacy.ErasureReviewPolicy; p != "delete" && p != "anonymize" {
+		errs = append(errs, fmt.Errorf("ERASURE_REVIEW_POLICY must be delete or anonymize, got %q", p))
+	}
+	if c.Privacy.UploadDir == "" {
+		errs = append(errs, errors.New("UPLOAD_DIR is required"))
+	}
+	if c.Tracing.Enabled {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) URL, got %q", c.Tracing.Endpoint))
+		}
+		if c.Tracing.ServiceName == "" {
+			errs = append(errs, errors.New("OTEL_SERVICE_NAME is required"))
+		}
+	}
+	if r := c.Tracing.SampleRatio; r < 0 || r > 1 {
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLE_RATIO must be between 0 and 1, got %v", r))
+	}
+	switch c.Cache.Backend {
+	case CacheNone:
+	case CacheMemory, CacheRedis:
+		if c.Cache.TTL <= 0 {
+			errs = append(errs, errors.New("CACHE_TTL must be positive"))
+		}
+		if c.Cache.Backend == CacheMemory && c.Cache.MaxEntries <= 0 {
+			errs = append(errs, errors.New("CACHE_MAX_ENTRIES must be positive"))
+		}
+		if c.Cache.Backend == CacheRedis && (c.Cache.RedisAddr == "" || c.Cache.RedisPoolSize < 0 || c.Cache.RedisTimeout <= 0) {
+			errs = append(errs, errors.New("REDIS_ADDR is required, REDIS_POOL_SIZE must not be negative and REDIS_TIMEOUT must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("CACHE_BACKEND must be none, memory or redis, got %q", c.Cache.Backend))
+	}
+	if err := c.Jobs.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Outbox.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Webhooks.Timeout <= 0 || c.Webhooks.DisableAfter <= 0 || c.Webhooks.DeliveryRetention <= 0 {
+		errs = append(errs, errors.New("WEBHOOKS_TIMEOUT, WEBHOOKS_DISABLE_AFTER and WEBHOOKS_DELIVERY_RETENTION must be positive"))
+	}
+	if c.Idempotency.TTL <= 0 || c.Idempotency.LockTimeout <= 0 {
+		errs = append(errs, errors.New("IDEMPOTENCY_TTL and IDEMPOTENCY_LOCK_TIMEOUT must be positive"))
+	}
+	if c.Outbox.Stream != "" && c.Cache.RedisAddr == "" {
+		errs = append(errs, errors.New("REDIS_ADDR is required when OUTBOX_STREAM is set"))
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateJWTSecret() error {
+	secret := c.Auth.JWTSecret
+	if secret == "" {
+		return errors.New("JWT_SECRET is required")
+	}
+	for _, weak := range weakJWTSecrets {
+		if strings.EqualFold(secret, weak) {
+			return errors.New("JWT_SECRET is a well-known placeholder")
+		}
+	}
+	if len(secret) < minJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d bytes", minJWTSecretLength)
+	}
+	return nil
+}
+
+func randomSecret() string {
+	b := make([]byte, minJWTSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate JWT secret: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
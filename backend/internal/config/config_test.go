@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns the defaults completed with the settings that have
+// none, so that Validate accepts it
+func validConfig() *Config {
+	cfg := Default()
+	cfg.DB.User = "root"
+	cfg.DB.Host = "db"
+	cfg.DB.Port = "3306"
+	cfg.DB.Name = "protein"
+	cfg.Auth.JWTSecret = strings.Repeat("k", minJWTSecretLength)
+	return cfg
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string // substrings of the error, none when valid
+	}{
+		{name: "valid", change: func(c *Config) {}},
+		{
+			name:   "unknown environment",
+			change: func(c *Config) { c.Env = "staging" },
+			want:   []string{`APP_ENV must be development or production, got "staging"`},
+		},
+		{
+			name:   "non-positive timeout",
+			change: func(c *Config) { c.HTTP.ReadTimeout = 0 },
+			want:   []string{"HTTP_READ_TIMEOUT must be positive"},
+		},
+		{
+			name:   "write timeout shorter than the request deadline",
+			change: func(c *Config) { c.HTTP.WriteTimeout = time.Second },
+			want:   []string{"HTTP_WRITE_TIMEOUT must not be shorter than REQUEST_TIMEOUT"},
+		},
+		{
+			name:   "no sunset",
+			change: func(c *Config) { c.HTTP.UnversionedSunset = time.Time{} },
+			want:   []string{"API_UNVERSIONED_SUNSET must be set"},
+		},
+		{
+			name:   "missing database settings",
+			change: func(c *Config) { c.DB.Host, c.DB.Name = "", "" },
+			want:   []string{"DB_HOST is required", "DB_NAME is required"},
+		},
+		{
+			name:   "unknown moderation action",
+			change: func(c *Config) { c.Moderation.SpamAction = "block" },
+			want:   []string{`MODERATION_SPAM_ACTION must be one of allow, mask, flag, reject, got "block"`},
+		},
+		{
+			name:   "unreadable banned word list",
+			change: func(c *Config) { c.Moderation.BannedWordsFile = filepath.Join(t.TempDir(), "missing.txt") },
+			want:   []string{"MODERATION_BANNED_WORDS_FILE cannot be read"},
+		},
+		{
+			name:   "tracing without an endpoint",
+			change: func(c *Config) { c.Tracing.Enabled, c.Tracing.Endpoint = true, "collector:4318" },
+			want:   []string{"OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) URL"},
+		},
+		{
+			name:   "redis cache without an address",
+			change: func(c *Config) { c.Cache.Backend, c.Cache.RedisAddr = CacheRedis, "" },
+			want:   []string{"REDIS_ADDR is required"},
+		},
+		{
+			name:   "outbox without attempts",
+			change: func(c *Config) { c.Outbox.MaxAttempts = 0 },
+			want:   []string{"OUTBOX_MAX_ATTEMPTS must be positive"},
+		},
+		{
+			name:   "unknown log level",
+			change: func(c *Config) { c.Log.Level = "verbose" },
+			want:   []string{`LOG_LEVEL must be debug, info, warn or error, got "verbose"`},
+		},
+		{
+			name: "every problem is reported",
+			change: func(c *Config) {
+				c.HTTP.Addr = ""
+				c.Auth.TokenTTL = 0
+				c.Privacy.ErasureReviewPolicy = "keep"
+			},
+			want: []string{
+				"HTTP_ADDR is required",
+				"JWT_TTL must be positive",
+				`ERASURE_REVIEW_POLICY must be delete or anonymize, got "keep"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.change(cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate = nil, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateJWTSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		want   string
+	}{
+		{name: "strong", secret: strings.Repeat("k", minJWTSecretLength)},
+		{name: "empty", want: "JWT_SECRET is required"},
+		{name: "placeholder", secret: "Your-Secret-Key", want: "JWT_SECRET is a well-known placeholder"},
+		{name: "short", secret: strings.Repeat("k", minJWTSecretLength-1), want: "JWT_SECRET must be at least 32 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Production refuses the secret
+			cfg := validConfig()
+			cfg.Env = EnvProduction
+			cfg.Auth.JWTSecret = tt.secret
+			err := cfg.Validate()
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("production: Validate = %v, want %q", err, tt.want)
+			}
+
+			// Development only warns about it
+			var logs bytes.Buffer
+			defer slog.SetDefault(slog.Default())
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			cfg.Env = EnvDevelopment
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("development: Validate = %v", err)
+			}
+			if warned := strings.Contains(logs.String(), "weak JWT secret"); warned != (tt.want != "") {
+				t.Errorf("development: warned = %v; logs: %s", warned, logs.String())
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	noEnvFile := filepath.Join(t.TempDir(), ".env")
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", "")
+		cfg, err := Load(noEnvFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.HTTP.Addr != ":8080" || cfg.HTTP.RequestTimeout != 10*time.Second {
+			t.Errorf("http = %+v, want the defaults", cfg.HTTP)
+		}
+	})
+
+	t.Run("environment overrides the file", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", `
+http:
+  addr: ":9000"
+  request_timeout: 5s
+  unversioned_sunset: 2027-06-01
+db:
+  host: yaml-db
+  name: protein
+`))
+		t.Setenv("HTTP_ADDR", ":9100")
+		t.Setenv("DB_HOST", "env-db")
+		t.Setenv("DB_NAME", "") // empty variables are ignored
+
+		cfg, err := Load(noEnvFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.HTTP.Addr != ":9100" || cfg.DB.Host != "env-db" {
+			t.Errorf("addr = %q, host = %q; want the environment", cfg.HTTP.Addr, cfg.DB.Host)
+		}
+		if cfg.HTTP.RequestTimeout != 5*time.Second || cfg.DB.Name != "protein" {
+			t.Errorf("request timeout = %v, name = %q; want the file", cfg.HTTP.RequestTimeout, cfg.DB.Name)
+		}
+		if want := time.Date(2027, time.June, 1, 0, 0, 0, 0, time.UTC); !cfg.HTTP.UnversionedSunset.Equal(want) {
+			t.Errorf("sunset = %v, want %v", cfg.HTTP.UnversionedSunset, want)
+		}
+		if cfg.HTTP.ReadTimeout != 15*time.Second {
+			t.Errorf("read timeout = %v, want the default", cfg.HTTP.ReadTimeout)
+		}
+	})
+
+	t.Run("unknown file setting", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "http:\n  adress: \":9000\"\n"))
+		if _, err := Load(noEnvFile); err == nil {
+			t.Error("Load accepted a misspelled setting")
+		}
+	})
+
+	t.Run("invalid environment value", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", "")
+		t.Setenv("REQUEST_TIMEOUT", "10")
+		_, err := Load(noEnvFile)
+		if err == nil || !strings.Contains(err.Error(), "invalid REQUEST_TIMEOUT") {
+			t.Errorf("Load = %v, want an invalid REQUEST_TIMEOUT", err)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type userKey struct{}
+
+// WithUser marks ctx as acting for userID. Reads in that context see the
+// user's own recent writes even when a replica is configured.
+func WithUser(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+func userFromContext(ctx context.Context) int {
+	userID, _ := ctx.Value(userKey{}).(int)
+	return userID
+}
+
+// Cluster routes queries between the primary and an optional read replica.
+// Writes and ordinary queries always go to the primary, inside the
+// transaction started by InTx if there is one. Repositories send
+// reads that tolerate replication lag through Reader, which picks the
+// replica unless it is unhealthy or the user wrote within the stickiness
+// window (read-your-writes).
+type Cluster struct {
+	primary    *sql.DB
+	replica    *sql.DB
+	stickiness time.Duration
+	healthy    atomic.Bool
+
+	mu         sync.Mutex
+	lastWrites map[int]time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// ReplicaOptions configure replica routing
+type ReplicaOptions struct {
+	// Stickiness is how long after a write the user's reads stay on the primary
+	Stickiness time.Duration
+	// CheckInterval is how often the replica is pinged; PingTimeout bounds each ping
+	CheckInterval time.Duration
+	PingTimeout   time.Duration
+}
+
+// NewCluster wraps primary. replica may be nil, in which case every read
+// goes to the primary and no health checks run.
+func NewCluster(primary, replica *sql.DB, opts ReplicaOptions) *Cluster {
+	c := &Cluster{
+		primary:    primary,
+		replica:    replica,
+		stickiness: opts.Stickiness,
+		lastWrites: make(map[int]time.Time),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if replica == nil {
+		close(c.done)
+		return c
+	}
+
+	c.check(opts.PingTimeout)
+	if !c.healthy.Load() {
+		slog.Warn("read replica is not reachable, routing reads to the primary until it is")
+	}
+	go c.monitor(opts.CheckInterval, opts.PingTimeout)
+	return c
+}
+
+// Primary returns the pool that receives writes
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+// Replica returns the replica pool, or nil when none is configured
+func (c *Cluster) Replica() *sql.DB {
+	return c.replica
+}
+
+// Reader returns the pool for a read that may be slightly stale
+func (c *Cluster) Reader(ctx context.Context) *sql.DB {
+	if c.replica == nil || !c.healthy.Load() {
+		return c.primary
+	}
+	if userID := userFromContext(ctx); userID != 0 && c.wroteRecently(userID) {
+		return c.primary
+	}
+	return c.replica
+}
+
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	c.markWrite(ctx)
+	return Conn(ctx, c.primary).ExecContext(ctx, query, args...)
+}
+
+func (c *Cluster) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	c.markWrite(ctx)
+	return c.primary.BeginTx(ctx, opts)
+}
+
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return Conn(ctx, c.primary).QueryContext(ctx, query, args...)
+}
+
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return Conn(ctx, c.primary).QueryRowContext(ctx, query, args...)
+}
+
+// Close stops the health checks and closes both pools
+func (c *Cluster) Close() error {
+	if c.replica == nil {
+		return c.primary.Close()
+	}
+	select {
+	case <-c.stop:
+	default:
+		close(c.stop)
+	}
+	<-c.done
+	return errors.Join(c.replica.Close(), c.primary.Close())
+}
+
+func (c *Cluster) markWrite(ctx context.Context) {
+	userID := userFromContext(ctx)
+	if c.replica == nil || userID == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.lastWrites[userID] = time.Now()
+	c.mu.Unlock()
+}
+
+func (c *Cluster) wroteRecently(userID int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at, ok := c.lastWrites[userID]
+	return ok && time.Since(at) < c.stickiness
+}
+
+func (c *Cluster) monitor(interval, timeout time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.check(timeout)
+			c.forgetOldWrites()
+		}
+	}
+}
+
+// check pings the replica and logs when its health changes
+func (c *Cluster) check(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := c.replica.PingContext(ctx)
+	healthy := err == nil
+	if c.healthy.Swap(healthy) != healthy {
+		if healthy {
+			slog.Info("read replica is healthy, routing reads to it")
+		} else {
+			slog.Warn("read replica is unhealthy, routing reads to the primary", "error", err)
+		}
+	}
+}
+
+func (c *Cluster) forgetOldWrites() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for userID, at := range c.lastWrites {
+		if time.Since(at) >= c.stickiness {
+			delete(c.lastWrites, userID)
+		}
+	}
+}
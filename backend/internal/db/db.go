@@ -0,0 +1,107 @@
+// Package db opens the MySQL connection pool shared by the server and the
+// migrate command.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/tracing"
+)
+
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+// DSN returns the go-sql-driver/mysql data source name for cfg
+func DSN(cfg config.DBConfig) string {
+	dsn := mysql.NewConfig()
+	dsn.User = cfg.User
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(cfg.Host, cfg.Port)
+	dsn.DBName = cfg.Name
+	dsn.ParseTime = true
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	return dsn.FormatDSN()
+}
+
+// Open creates the pool and waits until the database answers. While it does
+// not, the ping is retried with exponential backoff until cfg.ConnectTimeout
+// has passed or ctx is done.
+func Open(ctx context.Context, cfg config.DBConfig) (*sql.DB, error) {
+	db, err := tracing.OpenDB("mysql", DSN(cfg))
+	if err != nil {
+		return nil, err
+	}
+	configurePool(db, cfg)
+
+	if err := waitForDatabase(ctx, db, cfg.ConnectTimeout); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenCluster opens the primary like Open and, when configured, the read
+// replica. The replica is not waited for: until it answers, reads go to
+// the primary.
+func OpenCluster(ctx context.Context, cfg config.DBConfig) (*Cluster, error) {
+	primary, err := Open(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaCfg, ok := cfg.Replica()
+	if !ok {
+		return NewCluster(primary, nil, ReplicaOptions{}), nil
+	}
+	replica, err := tracing.OpenDB("mysql", DSN(replicaCfg))
+	if err != nil {
+		primary.Close()
+		return nil, err
+	}
+	configurePool(replica, replicaCfg)
+
+	return NewCluster(primary, replica, ReplicaOptions{
+		Stickiness:    cfg.ReplicaStickiness,
+		CheckInterval: cfg.ReplicaCheckInterval,
+		PingTimeout:   cfg.PingTimeout,
+	}), nil
+}
+
+func configurePool(db *sql.DB, cfg config.DBConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+func waitForDatabase(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		slog.Warn("database is not reachable yet, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
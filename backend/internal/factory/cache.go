@@ -0,0 +1,39 @@
+package factory
+
+import (
+	"protein-web-backend/internal/cache"
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/metrics"
+	"protein-web-backend/internal/service"
+)
+
+// newCache builds the configured cache backend. Redis connects on first
+// use, so a server that is still starting does not stop the API.
+func newCache(cfg config.CacheConfig, m *metrics.Metrics) cache.Cache {
+	var c cache.Cache
+	switch cfg.Backend {
+	case config.CacheRedis:
+		c = cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+			PoolSize: cfg.RedisPoolSize,
+			Timeout:  cfg.RedisTimeout,
+		})
+	case config.CacheMemory:
+		c = cache.NewMemory(cfg.MaxEntries)
+	default:
+		return cache.Nop{}
+	}
+	// ヒット率はデコレーターで記録する
+	return metrics.Cache(c, m)
+}
+
+// NewReviewCache returns the cache of reviews and feed pages, or nil when
+// caching is disabled
+func (f *Factory) NewReviewCache() *service.ReviewCache {
+	if f.Config.Cache.Backend == config.CacheNone {
+		return nil
+	}
+	return service.NewReviewCache(f.Cache, f.Config.Cache.TTL)
+}
@@ -2,20 +2,66 @@ package factory
 
 import (
 	"database/sql"
+	"net/http"
+
+	"protein-web-backend/internal/auth"
+	"protein-web-backend/internal/cache"
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/metrics"
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/realtime"
 )
 
 // Factory manages the creation of all application dependencies
 type Factory struct {
-	DB *sql.DB
+	// DB はプライマリ。読み取りをレプリカに振り分けるリポジトリは Cluster を使う
+	DB      *sql.DB
+	Cluster *db.Cluster
+	Hub     realtime.Hub
+	Config  *config.Config
+	// Tokens は発行（UserService）と検証（middleware.Auth）で共有する
+	Tokens *auth.Tokens
+	// Metrics はリポジトリ・サービスのデコレーターと HTTP ミドルウェアで共有する
+	Metrics *metrics.Metrics
+	// Cache は ReviewCache が使う。閉じるのはリクエストが終わった後
+	Cache cache.Cache
+	// Broker はイベントを Redis ストリームへ送る。OUTBOX_STREAM が空なら nil
+	Broker *cache.Redis
 }
 
 // New creates a new Factory instance
-func New(db *sql.DB) *Factory {
+func New(cluster *db.Cluster, cfg *config.Config) *Factory {
+	m := metrics.New(cluster.Primary(), cluster.Replica())
 	return &Factory{
-		DB: db,
+		DB:      cluster.Primary(),
+		Cluster: cluster,
+		Hub:     realtime.NewMemoryHub(realtime.DefaultBufferSize),
+		Config:  cfg,
+		Tokens:  auth.NewTokens(cfg.Auth.JWTSecret, cfg.Auth.TokenTTL),
+		Metrics: m,
+		Cache:   newCache(cfg.Cache, m),
+		Broker:  newBroker(cfg),
 	}
 }
 
+// Close stops background work owned by the factory. Open event streams end
+// so that their requests can complete during a graceful shutdown.
+func (f *Factory) Close() {
+	f.Hub.Close()
+}
+
+// NewAuthMiddleware creates the authentication middleware
+func (f *Factory) NewAuthMiddleware() *middleware.Auth {
+	return middleware.NewAuth(f.Tokens)
+}
+
+// NewIdempotencyMiddleware creates the middleware that replays responses of
+// requests retried with the same Idempotency-Key
+func (f *Factory) NewIdempotencyMiddleware(repos *Repositories) func(http.Handler) http.Handler {
+	return middleware.Idempotency(repos.Idempotency, f.Config.Idempotency.TTL, f.Config.Idempotency.LockTimeout)
+}
+
 // NewAppComponents creates all application components in the correct order
 func (f *Factory) NewAppComponents() (*Repositories, *Services, *Handlers) {
 	repos := f.NewRepositories()
@@ -4,10 +4,27 @@ import (
 	"protein-web-backend/internal/handler"
 )
 
-// Handlers holds all handler instances
+// Handlers holds handler instances per API version. A version only needs its
+// own handler where its request or response shape differs; unchanged
+// endpoints can share the previous version's handler.
 type Handlers struct {
+	V1 *V1Handlers
+	// Health はバージョンに依存しないプローブ用ハンドラー
+	Health *handler.HealthHandler
+	// 新しいAPIバージョンを追加する場合はここに追加
+	// V2 *V2Handlers
+}
+
+// V1Handlers holds the handlers mounted under /api/v1
+type V1Handlers struct {
 	User *handler.UserHandler
 	Review *handler.ReviewHandler
+	Stream *handler.StreamHandler
+	Report *handler.ReportHandler
+	Moderation *handler.ModerationHandler
+	Audit *handler.AuditHandler
+	Privacy *handler.PrivacyHandler
+	Webhook *handler.WebhookHandler
 	// 新しいハンドラーを追加する場合はここに追加
 	// Product *handler.ProductHandler
 }
@@ -15,9 +32,22 @@ type Handlers struct {
 // NewHandlers creates and returns all handler instances
 func (f *Factory) NewHandlers(services *Services) *Handlers {
 	return &Handlers{
-		User: handler.NewUserHandler(services.User),
+		V1: f.newV1Handlers(services),
+		Health: handler.NewHealthHandler(services.Health),
+	}
+}
+
+func (f *Factory) newV1Handlers(services *Services) *V1Handlers {
+	return &V1Handlers{
+		User: handler.NewUserHandler(services.User, f.Config.Auth.TokenTTL),
 		Review: handler.NewReviewHandler(services.Review),
+		Stream: handler.NewStreamHandler(f.Hub),
+		Report: handler.NewReportHandler(services.Report),
+		Moderation: handler.NewModerationHandler(services.Moderation),
+		Audit: handler.NewAuditHandler(services.Audit),
+		Privacy: handler.NewPrivacyHandler(services.Privacy),
+		Webhook: handler.NewWebhookHandler(services.Webhook),
 		// 新しいハンドラーの初期化を追加（サービスを注入）
 		// Product: handler.NewProductHandler(services.Product),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,82 @@
+package factory
+
+import (
+	"context"
+	"time"
+
+	"protein-web-backend/internal/jobs"
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/service"
+)
+
+const (
+	jobPurgeFinished    = "jobs.purge_finished"
+	jobPurgePublished   = "outbox.purge_published"
+	jobPurgeDeliveries  = "webhooks.purge_deliveries"
+	jobPurgeIdempotency = "idempotency.purge_expired"
+)
+
+// NewJobQueue creates the queue services use to enqueue background work
+func (f *Factory) NewJobQueue(repos *Repositories) *jobs.Queue {
+	return jobs.NewQueue(repos.Job, f.Config.Jobs.MaxAttempts)
+}
+
+// NewJobWorker registers the job handlers and schedules. The API server
+// and cmd/worker both run the worker it returns.
+func (f *Factory) NewJobWorker(repos *Repositories, services *Services) *jobs.Worker {
+	cfg := f.Config.Jobs
+	registry := jobs.NewRegistry()
+
+	// 完了したジョブを保持期間が過ぎたら削除する
+	jobs.Register(registry, jobPurgeFinished, func(ctx context.Context, _ struct{}) error {
+		purged, err := repos.Job.PurgeFinished(ctx, cfg.Retention)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("purged finished jobs", "count", purged)
+		return nil
+	})
+	// 配信済みのイベントと処理済みキーを保持期間が過ぎたら削除する
+	jobs.Register(registry, jobPurgePublished, func(ctx context.Context, _ struct{}) error {
+		purged, err := repos.Outbox.PurgePublished(ctx, f.Config.Outbox.Retention)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("purged published outbox events", "count", purged)
+		return nil
+	})
+	// Webhook の配信ログを保持期間が過ぎたら削除する
+	jobs.Register(registry, jobPurgeDeliveries, func(ctx context.Context, _ struct{}) error {
+		purged, err := repos.Webhook.PurgeDeliveries(ctx, f.Config.Webhooks.DeliveryRetention)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("purged webhook deliveries", "count", purged)
+		return nil
+	})
+	// 期限切れの冪等キーと保存したレスポンスを削除する
+	jobs.Register(registry, jobPurgeIdempotency, func(ctx context.Context, _ struct{}) error {
+		purged, err := repos.Idempotency.PurgeExpired(ctx)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("purged expired idempotency keys", "count", purged)
+		return nil
+	})
+	jobs.Register(registry, service.JobWebhookDeliver, services.Webhook.Deliver)
+	// 新しいジョブを追加する場合はここに登録
+	// jobs.Register(registry, "product.reindex", services.Product.Reindex)
+
+	worker := jobs.NewWorker(repos.Job, f.NewJobQueue(repos), registry, jobs.WorkerOptions{
+		Concurrency:  cfg.Concurrency,
+		PollInterval: cfg.PollInterval,
+		Lease:        cfg.Lease,
+		BackoffBase:  cfg.BackoffBase,
+		BackoffMax:   cfg.BackoffMax,
+	})
+	worker.Schedule(jobs.Schedule{Name: "purge-finished-jobs", Type: jobPurgeFinished, Every: time.Hour, Payload: struct{}{}})
+	worker.Schedule(jobs.Schedule{Name: "purge-published-events", Type: jobPurgePublished, Every: time.Hour, Payload: struct{}{}})
+	worker.Schedule(jobs.Schedule{Name: "purge-webhook-deliveries", Type: jobPurgeDeliveries, Every: time.Hour, Payload: struct{}{}})
+	worker.Schedule(jobs.Schedule{Name: "purge-idempotency-keys", Type: jobPurgeIdempotency, Every: time.Hour, Payload: struct{}{}})
+	return worker
+}
@@ -0,0 +1,38 @@
+package factory
+
+import (
+	"fmt"
+
+	"protein-web-backend/internal/moderation"
+)
+
+// NewModerationChain builds the content filter chain from the moderation
+// settings. The spam filter runs first so it sees the text before other
+// filters mask it. The banned word file was checked by config.Validate, so
+// failing to read it here stops the server rather than letting every banned
+// word through.
+func (f *Factory) NewModerationChain() *moderation.Chain {
+	cfg := f.Config.Moderation
+
+	var words []string
+	if cfg.BannedWordsFile != "" {
+		loaded, err := moderation.LoadWordList(cfg.BannedWordsFile)
+		if err != nil {
+			panic(fmt.Sprintf("failed to load banned word list %s: %v", cfg.BannedWordsFile, err))
+		}
+		words = loaded
+	}
+
+	return moderation.NewChain(
+		moderation.NewSpamFilter(moderationAction(cfg.SpamAction)),
+		moderation.NewBannedWordFilter(words, moderationAction(cfg.BannedWordsAction)),
+		moderation.NewLinkFilter(moderationAction(cfg.LinksAction), cfg.AllowedDomains),
+		moderation.NewPhoneNumberFilter(moderationAction(cfg.PhoneAction)),
+	)
+}
+
+// moderationAction converts a setting already checked by config.Validate
+func moderationAction(name string) moderation.Action {
+	action, _ := moderation.ParseAction(name)
+	return action
+}
@@ -0,0 +1,88 @@
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"protein-web-backend/internal/cache"
+	"protein-web-backend/internal/config"
+	"protein-web-backend/internal/metrics"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/outbox"
+	"protein-web-backend/internal/realtime"
+)
+
+// newBroker connects to the Redis server that receives events on a stream,
+// or returns nil when no stream is configured
+func newBroker(cfg *config.Config) *cache.Redis {
+	if cfg.Outbox.Stream == "" {
+		return nil
+	}
+	return cache.NewRedis(cache.RedisOptions{
+		Addr:     cfg.Cache.RedisAddr,
+		Password: cfg.Cache.RedisPassword,
+		DB:       cfg.Cache.RedisDB,
+		PoolSize: cfg.Cache.RedisPoolSize,
+		Timeout:  cfg.Cache.RedisTimeout,
+	})
+}
+
+// NewOutboxRelay subscribes the in-process consumers and returns the relay
+// that publishes outbox events to them and to the configured webhook and
+// stream. It runs in the API server because the realtime hub only reaches
+// clients connected to that process.
+//
+// Realtime notifications therefore assume a single API instance. Each event
+// is claimed by the relay of one instance, so with several instances a
+// client only hears of the events its own instance relayed; a per-instance
+// consumer id would not change that. Scaling out needs a broker-backed
+// realtime.Hub fed from OUTBOX_STREAM.
+func (f *Factory) NewOutboxRelay(repos *Repositories, services *Services) *outbox.Relay {
+	cfg := f.Config.Outbox
+	bus := outbox.NewBus()
+
+	// 接続中のクライアントへ新着レビューを通知する（非表示のレビューは除く）
+	bus.Subscribe(model.EventReviewCreated, outbox.Idempotent(repos.Outbox, "realtime", func(ctx context.Context, event *model.OutboxEvent) error {
+		var payload model.ReviewEventPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+		if payload.Hidden {
+			return nil
+		}
+		f.Hub.Publish(realtime.Event{
+			Type:      realtime.EventReviewCreated,
+			Data:      realtime.ReviewEvent{ReviewID: payload.ReviewID, UserID: payload.UserID},
+			CreatedAt: event.CreatedAt,
+		})
+		return nil
+	}))
+	// 購読している Webhook ごとに配信ジョブを積む（ジョブの一意キーで重複を防ぐ）
+	for _, eventType := range model.WebhookEventTypes {
+		bus.Subscribe(eventType, services.Webhook.Dispatch)
+	}
+	// 新しいイベントの購読者を追加する場合はここに登録
+	// bus.Subscribe(model.EventReviewDeleted, outbox.Idempotent(repos.Outbox, "search", services.Search.Remove))
+
+	sinks := []outbox.Sink{bus}
+	if cfg.WebhookURL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout))
+	}
+	if f.Broker != nil {
+		sinks = append(sinks, outbox.NewStreamSink(f.Broker, cfg.Stream, cfg.StreamMaxLen))
+	}
+	for i, sink := range sinks {
+		// 配信結果はデコレーターで記録する
+		sinks[i] = metrics.Sink(sink, f.Metrics)
+	}
+
+	return outbox.NewRelay(repos.Outbox, sinks, outbox.RelayOptions{
+		BatchSize:    cfg.BatchSize,
+		PollInterval: cfg.PollInterval,
+		Lease:        cfg.Lease,
+		MaxAttempts:  cfg.MaxAttempts,
+		BackoffBase:  cfg.BackoffBase,
+		BackoffMax:   cfg.BackoffMax,
+	})
+}
@@ -1,6 +1,7 @@
 package factory
 
 import (
+	"protein-web-backend/internal/metrics"
 	"protein-web-backend/internal/repository"
 )
 
@@ -8,6 +9,14 @@ import (
 type Repositories struct {
 	User repository.UserRepository
 	Review repository.ReviewRepository
+	Report repository.ReportRepository
+	Moderation repository.ModerationRepository
+	Audit repository.AuditRepository
+	Health repository.HealthRepository
+	Job repository.JobRepository
+	Outbox repository.OutboxRepository
+	Webhook repository.WebhookRepository
+	Idempotency repository.IdempotencyRepository
 	// 新しいリポジトリを追加する場合はここに追加
 	// Product repository.ProductRepository
 }
@@ -15,8 +24,19 @@ type Repositories struct {
 // NewRepositories creates and returns all repository instances
 func (f *Factory) NewRepositories() *Repositories {
 	return &Repositories{
-		User: repository.NewUserRepository(f.DB),
-		Review: repository.NewReviewRepository(f.DB),
+		// クエリのレイテンシを計測するデコレーターで包む
+		User: metrics.UserRepository(repository.NewUserRepository(f.DB), f.Metrics),
+		Review: metrics.ReviewRepository(repository.NewReviewRepository(f.Cluster), f.Metrics),
+		Report: repository.NewReportRepository(f.DB),
+		Moderation: repository.NewModerationRepository(f.DB),
+		Audit: repository.NewAuditRepository(f.DB),
+		Health: repository.NewHealthRepository(f.DB),
+		// ジョブキューは常にプライマリを使う
+		Job: repository.NewJobRepository(f.DB),
+		// ドメインの変更と同じトランザクションで書き込むためプライマリを使う
+		Outbox: repository.NewOutboxRepository(f.DB),
+		Webhook: repository.NewWebhookRepository(f.DB),
+		Idempotency: repository.NewIdempotencyRepository(f.DB),
 		// 新しいリポジトリの初期化を追加
 		// Product: repository.NewProductRepository(f.DB),
 	}
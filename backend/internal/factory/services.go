@@ -1,23 +1,49 @@
 package factory
 
 import (
+	"protein-web-backend/internal/metrics"
+	"protein-web-backend/internal/repository"
 	"protein-web-backend/internal/service"
+	"protein-web-backend/internal/webhook"
+	"protein-web-backend/migrations"
 )
 
 // Services holds all service instances
 type Services struct {
 	User service.UserService
 	Review service.ReviewService
+	Report service.ReportService
+	Moderation service.ModerationService
+	Audit service.AuditService
+	Privacy service.PrivacyService
+	Health service.HealthService
+	Webhook service.WebhookService
 	// 新しいサービスを追加する場合はここに追加
 	// Product service.ProductService
 }
 
 // NewServices creates and returns all service instances
 func (f *Factory) NewServices(repos *Repositories) *Services {
+	moderationService := service.NewModerationService(repos.Moderation, f.NewModerationChain())
+	auditService := service.NewAuditService(repos.Audit)
+	// 書き込みで無効化するため、レビューを変更する全サービスで共有する
+	reviewCache := f.NewReviewCache()
+	// 複数のリポジトリへの書き込みとイベントの記録を一つのトランザクションにまとめる
+	transactor := repository.NewTransactor(f.DB)
+
 	return &Services{
-		User: service.NewUserService(repos.User),
-		Review: service.NewReviewService(repos.Review, repos.User),
+		// ドメインイベントのカウンターはデコレーターで記録する
+		User: metrics.UserService(service.NewUserService(repos.User, auditService, transactor, f.Tokens, reviewCache), f.Metrics),
+		Review: metrics.ReviewService(service.NewReviewService(repos.Review, repos.User, moderationService, auditService, transactor, repos.Outbox, reviewCache), f.Metrics),
+		Report: service.NewReportService(repos.Report, repos.Review, auditService, transactor, f.Config.Reports.HideThreshold, reviewCache),
+		Moderation: moderationService,
+		Audit: auditService,
+		Privacy: service.NewPrivacyService(repos.User, repos.Review, repos.Report, auditService, transactor, service.ErasurePolicy(f.Config.Privacy.ErasureReviewPolicy), f.Config.Privacy.UploadDir, reviewCache),
+		// 期待するスキーマバージョンは埋め込んだマイグレーションファイルから決まる
+		Health: service.NewHealthService(repos.Health, migrations.LatestVersion(), f.Config.DB.PingTimeout),
+		// 配信はジョブキュー経由で行い、再試行はワーカーに任せる
+		Webhook: service.NewWebhookService(repos.Webhook, auditService, f.NewJobQueue(repos), webhook.NewSender(f.Config.Webhooks.Timeout), f.Config.Webhooks.DisableAfter),
 		// 新しいサービスの初期化を追加（リポジトリを注入）
 		// Product: service.NewProductService(repos.Product),
 	}
-}
\ No newline at end of file
+}
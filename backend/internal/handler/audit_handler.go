@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLog returns audit entries newest first, filtered by the optional
+// entityType, entityId and actorId query parameters.
+func (h *AuditHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := model.AuditLogFilter{EntityType: query.Get("entityType")}
+	filter.EntityID, _ = strconv.Atoi(query.Get("entityId"))
+	filter.ActorID, _ = strconv.Atoi(query.Get("actorId"))
+
+	limit, offset := pagination(r)
+
+	entries, err := h.auditService.List(r.Context(), filter, limit, offset)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if entries == nil {
+		entries = []*model.AuditLogEntry{}
+	}
+
+	respond.JSON(w, http.StatusOK, entries)
+}
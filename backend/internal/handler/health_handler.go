@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"protein-web-backend/internal/buildinfo"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+)
+
+// HealthHandler serves the probes used by the orchestrator. They sit outside
+// the versioned API and need no authentication.
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Liveness reports that the process is serving requests. It does not touch
+// the database so that a database outage does not get the process restarted.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, map[string]string{"status": model.HealthOK})
+}
+
+// Readiness returns 503 while the instance should not receive traffic
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	result := h.healthService.Readiness(r.Context())
+	status := http.StatusOK
+	if !result.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	respond.JSON(w, status, result)
+}
+
+// Version returns the build information of the running binary
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+)
+
+type ModerationHandler struct {
+	moderationService service.ModerationService
+}
+
+func NewModerationHandler(moderationService service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// ListResults returns the content filter audit log, optionally filtered by
+// ?action=mask|flag|reject.
+func (h *ModerationHandler) ListResults(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r)
+
+	results, err := h.moderationService.ListResults(r.Context(), r.URL.Query().Get("action"), limit, offset)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if results == nil {
+		results = []*model.ModerationResult{}
+	}
+
+	respond.JSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+	"protein-web-backend/internal/types"
+)
+
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+}
+
+func NewPrivacyHandler(privacyService service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// ExportData sends the authenticated user's data as a zip archive
+func (h *PrivacyHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+
+	export, err := h.privacyService.ExportUserData(r.Context(), userID)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("protein-web-export-%d-%s.zip", userID, export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	if err := h.privacyService.WriteArchive(r.Context(), export, w); err != nil {
+		// Headers are already sent; the client sees a truncated archive
+		logging.FromContext(r.Context()).Error("failed to write data export", "error", err)
+	}
+}
+
+// EraseAccount erases the authenticated user's account after re-checking
+// their password.
+func (h *PrivacyHandler) EraseAccount(w http.ResponseWriter, r *http.Request) {
+	var req types.EraseAccountRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.privacyService.EraseOwnAccount(r.Context(), middleware.UserIDFromContext(r.Context()), req.Password); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EraseUser erases any account (admin only), e.g. for requests received by support
+func (h *PrivacyHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.privacyService.EraseUser(r.Context(), middleware.UserIDFromContext(r.Context()), id); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+func (h *ReportHandler) ReportReview(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+
+	reviewID, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	var req model.CreateReportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	report, err := h.reportService.ReportReview(r.Context(), userID, reviewID, &req)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, report)
+}
+
+func (h *ReportHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	status := model.ReportStatus(r.URL.Query().Get("status"))
+	limit, offset := pagination(r)
+
+	reports, err := h.reportService.ListReports(r.Context(), status, limit, offset)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if reports == nil {
+		reports = []*model.Report{}
+	}
+
+	respond.JSON(w, http.StatusOK, reports)
+}
+
+func (h *ReportHandler) DismissReport(w http.ResponseWriter, r *http.Request) {
+	reportID, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid report ID")
+		return
+	}
+
+	if err := h.reportService.DismissReport(r.Context(), middleware.UserIDFromContext(r.Context()), reportID); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ReportHandler) HideReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := h.reportService.HideReview(r.Context(), middleware.UserIDFromContext(r.Context()), reviewID); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ReportHandler) RestoreReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := h.reportService.RestoreReview(r.Context(), middleware.UserIDFromContext(r.Context()), reviewID); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+	"protein-web-backend/internal/validate"
+)
+
+// maxRequestBodyBytes caps JSON request bodies. Reviews reference images by
+// URL, so no endpoint needs more than this.
+const maxRequestBodyBytes = 1 << 20
+
+var errTrailingData = errors.New("request body must contain a single JSON object")
+
+// decodeJSON decodes the request body into dst and validates it against its
+// struct tags. Unknown fields, trailing data and oversized bodies are
+// rejected. On failure the problem response has already been written and
+// false is returned.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errTrailingData
+	}
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return false
+	}
+
+	if fields := validate.Struct(dst); len(fields) > 0 {
+		respond.Error(w, r, &service.ValidationError{Message: "invalid request body", Fields: fields})
+		return false
+	}
+	return true
+}
+
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		tooLarge  *http.MaxBytesError
+		typeError *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &tooLarge):
+		respond.Status(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit))
+	case errors.As(err, &typeError):
+		respond.Error(w, r, service.NewFieldError(typeError.Field, "must be a "+typeError.Type.String()))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		respond.Error(w, r, service.NewFieldError(field, "is not allowed"))
+	case errors.Is(err, errTrailingData):
+		respond.Status(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, io.EOF):
+		respond.Status(w, r, http.StatusBadRequest, "request body must not be empty")
+	default:
+		respond.Status(w, r, http.StatusBadRequest, "Invalid JSON format")
+	}
+}
+
+// ifMatch reads the If-Match header of a write to a review. Writes must
+// send it, so without it the 428 response has already been written and
+// false is returned. Weak and unknown tags never match (RFC 9110 uses the
+// strong comparison for If-Match).
+func ifMatch(w http.ResponseWriter, r *http.Request) (service.Precondition, bool) {
+	var p service.Precondition
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		respond.Status(w, r, http.StatusPreconditionRequired, "If-Match header with the review's ETag is required")
+		return p, false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			p.Any = true
+			continue
+		}
+		if version, ok := parseReviewETag(tag); ok {
+			p.Versions = append(p.Versions, version)
+		}
+	}
+	return p, true
+}
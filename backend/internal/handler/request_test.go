@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/service"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   service.Precondition
+		status int // written when the header is rejected
+	}{
+		{name: "missing", status: http.StatusPreconditionRequired},
+		{name: "one version", header: `"v3"`, want: service.Precondition{Versions: []int{3}}},
+		{name: "several versions", header: `"v3", "v4"`, want: service.Precondition{Versions: []int{3, 4}}},
+		{name: "any", header: `*`, want: service.Precondition{Any: true}},
+		// Weak and unknown tags are kept out, so the write fails with 412
+		{name: "weak tag", header: `W/"v3"`},
+		{name: "unquoted", header: `v3`},
+		{name: "not a version", header: `"abc"`},
+		{name: "zero", header: `"v0"`},
+		{name: "unknown tag next to a version", header: `"x", "v5"`, want: service.Precondition{Versions: []int{5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/reviews/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			got, ok := ifMatch(w, r)
+			if ok != (tt.status == 0) {
+				t.Fatalf("ok = %v, want %v", ok, tt.status == 0)
+			}
+			if !ok {
+				if w.Code != tt.status {
+					t.Errorf("status = %d, want %d", w.Code, tt.status)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatch = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewETagRoundTrip(t *testing.T) {
+	for _, version := range []int{1, 42, 1 << 30} {
+		if got, ok := parseReviewETag(reviewETag(version)); !ok || got != version {
+			t.Errorf("parseReviewETag(reviewETag(%d)) = %d, %v", version, got, ok)
+		}
+	}
+}
+
+// deletingReviewService fails deletes whose precondition names another
+// version, like the real service does
+type deletingReviewService struct {
+	service.ReviewService
+	version int
+}
+
+func (s *deletingReviewService) DeleteReview(ctx context.Context, actorID, id int, ifMatch service.Precondition) error {
+	for _, v := range ifMatch.Versions {
+		if v == s.version {
+			return nil
+		}
+	}
+	if ifMatch.Any {
+		return nil
+	}
+	return &service.PreconditionFailedError{Message: "review has been modified since it was read"}
+}
+
+func TestDeleteReviewIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{name: "current version", header: `"v3"`, status: http.StatusNoContent},
+		{name: "any", header: `*`, status: http.StatusNoContent},
+		{name: "stale version", header: `"v2"`, status: http.StatusPreconditionFailed},
+		{name: "weak tag", header: `W/"v3"`, status: http.StatusPreconditionFailed},
+		{name: "missing", status: http.StatusPreconditionRequired},
+	}
+
+	h := NewReviewHandler(&deletingReviewService{version: 3})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/reviews/1", nil)
+			r.SetPathValue("id", "1")
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			h.DeleteReview(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d; body: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status >= 400 && w.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want a problem", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"protein-web-backend/internal/middleware"
 	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
 	"protein-web-backend/internal/service"
 )
 
@@ -25,134 +25,201 @@ func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
 	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		respond.Status(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	var req model.CreateReviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Create review
-	review, err := h.reviewService.CreateReview(userID, &req)
+	review, err := h.reviewService.CreateReview(r.Context(), userID, &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respond.Error(w, r, err)
 		return
 	}
 
 	// Convert to response format
 	response := h.toReviewResponse(review)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	w.Header().Set("ETag", reviewETag(review.Version))
+	respond.JSON(w, http.StatusCreated, response)
 }
 
 func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
-	// Extract ID from path: /api/reviews/{id}
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 4 {
-		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
 		return
 	}
 
-	id, err := strconv.Atoi(pathParts[3])
+	review, err := h.reviewService.GetReview(r.Context(), id, middleware.UserIDFromContext(r.Context()))
 	if err != nil {
-		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
 
-	review, err := h.reviewService.GetReview(id)
+	response := h.toReviewResponse(review)
+
+	w.Header().Set("ETag", reviewETag(review.Version))
+	respond.JSON(w, http.StatusOK, response)
+}
+
+// UpdateReview edits a review. If-Match must name the review's current ETag.
+func (h *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+	precondition, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateReviewRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	review, err := h.reviewService.UpdateReview(r.Context(), middleware.UserIDFromContext(r.Context()), id, precondition, &req)
 	if err != nil {
-		http.Error(w, "Review not found", http.StatusNotFound)
+		respond.Error(w, r, err)
 		return
 	}
 
 	response := h.toReviewResponse(review)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.Header().Set("ETag", reviewETag(review.Version))
+	respond.JSON(w, http.StatusOK, response)
 }
 
 func (h *ReviewHandler) GetAllReviews(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	limit := 20
-	offset := 0
+	limit, offset := pagination(r)
 
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
-		}
+	reviews, err := h.reviewService.GetAllReviews(r.Context(), middleware.UserIDFromContext(r.Context()), limit, offset)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
 	}
 
-	if o := r.URL.Query().Get("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+	// Convert to response format
+	responses := make([]model.ReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		responses = append(responses, *h.toReviewResponse(review))
 	}
 
-	reviews, err := h.reviewService.GetAllReviews(limit, offset)
+	respond.JSON(w, http.StatusOK, responses)
+}
+
+func (h *ReviewHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit, offset := pagination(r)
+
+	reviews, err := h.reviewService.GetUserReviews(r.Context(), userID, middleware.UserIDFromContext(r.Context()), limit, offset)
 	if err != nil {
-		http.Error(w, "Failed to get reviews", http.StatusInternalServerError)
+		respond.Error(w, r, err)
 		return
 	}
 
 	// Convert to response format
-	var responses []model.ReviewResponse
+	responses := make([]model.ReviewResponse, 0, len(reviews))
 	for _, review := range reviews {
 		responses = append(responses, *h.toReviewResponse(review))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(responses)
+	respond.JSON(w, http.StatusOK, responses)
 }
 
-func (h *ReviewHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
-	// Extract user ID from path: /api/users/{id}/reviews
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 4 {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
 		return
 	}
 
-	userID, err := strconv.Atoi(pathParts[3])
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	precondition, ok := ifMatch(w, r)
+	if !ok {
 		return
 	}
 
-	// Parse query parameters
-	limit := 20
-	offset := 0
+	if err := h.reviewService.DeleteReview(r.Context(), middleware.UserIDFromContext(r.Context()), id, precondition); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
 
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
-		}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ReviewHandler) DeleteReviewImage(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+	imageID, ok := pathID(r, "imageId")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid image ID")
+		return
 	}
 
-	if o := r.URL.Query().Get("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+	precondition, ok := ifMatch(w, r)
+	if !ok {
+		return
 	}
 
-	reviews, err := h.reviewService.GetUserReviews(userID, limit, offset)
-	if err != nil {
-		http.Error(w, "Failed to get user reviews", http.StatusInternalServerError)
+	if err := h.reviewService.DeleteReviewImage(r.Context(), middleware.UserIDFromContext(r.Context()), id, imageID, precondition); err != nil {
+		respond.Error(w, r, err)
 		return
 	}
 
-	// Convert to response format
-	var responses []model.ReviewResponse
-	for _, review := range reviews {
-		responses = append(responses, *h.toReviewResponse(review))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreReview undoes a soft delete (admin only)
+func (h *ReviewHandler) RestoreReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid review ID")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(responses)
+	if err := h.reviewService.RestoreReview(r.Context(), middleware.UserIDFromContext(r.Context()), id); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reviewETag identifies a version of a review. It changes whenever the review
+// does, so clients send it back in If-Match to detect concurrent edits.
+func reviewETag(version int) string {
+	return `"v` + strconv.Itoa(version) + `"`
+}
+
+// parseReviewETag is the inverse of reviewETag
+func parseReviewETag(tag string) (int, bool) {
+	digits, ok := strings.CutPrefix(tag, `"v`)
+	if !ok {
+		return 0, false
+	}
+	digits, ok = strings.CutSuffix(digits, `"`)
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(digits)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
 }
 
 func (h *ReviewHandler) toReviewResponse(review *model.Review) *model.ReviewResponse {
@@ -162,6 +229,8 @@ func (h *ReviewHandler) toReviewResponse(review *model.Review) *model.ReviewResp
 		ProteinPerServing: review.ProteinPerServing,
 		PricePerServing:   review.PricePerServing,
 		Comment:           review.Comment,
+		Hidden:            review.HiddenAt != nil,
+		Version:           review.Version,
 		Images:            make([]string, 0),
 	}
 
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/realtime"
+	"protein-web-backend/internal/respond"
+)
+
+const defaultHeartbeatInterval = 15 * time.Second
+
+type StreamHandler struct {
+	hub       realtime.Hub
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(hub realtime.Hub) *StreamHandler {
+	return &StreamHandler{
+		hub:       hub,
+		heartbeat: defaultHeartbeatInterval,
+	}
+}
+
+// Stream pushes events to the client as Server-Sent Events until the client
+// disconnects or the hub drops the subscription.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		respond.Status(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's read and write timeouts would cut the stream off; the
+	// heartbeat detects dead clients instead. Errors mean the writer does not
+	// support deadlines, in which case none apply.
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub := h.hub.Subscribe(userID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case event := <-sub.Events():
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event realtime.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
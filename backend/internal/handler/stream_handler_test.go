@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/realtime"
+)
+
+// streamServer serves h as userID, like the auth middleware would
+func streamServer(t *testing.T, h *StreamHandler, userID int) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Stream(w, r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// openStream connects to srv and reads up to the retry message, after which
+// the handler is subscribed
+func openStream(t *testing.T, srv *httptest.Server) (*bufio.Reader, io.Closer) {
+	t.Helper()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != "text/event-stream" {
+		resp.Body.Close()
+		t.Fatalf("status %d, Content-Type %q", resp.StatusCode, ct)
+	}
+	body := bufio.NewReader(resp.Body)
+	if msg := readMessage(t, body); msg != "retry: 3000\n" {
+		t.Fatalf("first message = %q, want the retry interval", msg)
+	}
+	return body, resp.Body
+}
+
+// readMessage reads one message, up to the blank line that ends it
+func readMessage(t *testing.T, body *bufio.Reader) string {
+	t.Helper()
+	var msg strings.Builder
+	for {
+		line, err := body.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read %q: %v", msg.String()+line, err)
+		}
+		if line == "\n" {
+			return msg.String()
+		}
+		msg.WriteString(line)
+	}
+}
+
+func TestStreamRequiresUser(t *testing.T) {
+	hub := realtime.NewMemoryHub(1)
+	defer hub.Close()
+	w := httptest.NewRecorder()
+
+	NewStreamHandler(hub).Stream(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestStreamDeliversEvents(t *testing.T) {
+	hub := realtime.NewMemoryHub(4)
+	defer hub.Close()
+	body, closer := openStream(t, streamServer(t, NewStreamHandler(hub), 7))
+	defer closer.Close()
+
+	createdAt := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+	hub.Publish(realtime.Event{Type: realtime.EventReviewCreated, UserIDs: []int{8}, CreatedAt: createdAt})
+	hub.Publish(realtime.Event{
+		Type:      realtime.EventReviewCreated,
+		Data:      realtime.ReviewEvent{ReviewID: 3, UserID: 8},
+		CreatedAt: createdAt,
+	})
+
+	// The event for user 8 alone is skipped
+	want := "id: 2\nevent: review.created\n" +
+		`data: {"id":"2","type":"review.created","data":{"reviewId":3,"userId":8},"createdAt":"2026-10-19T09:00:00Z"}` + "\n"
+	if msg := readMessage(t, body); msg != want {
+		t.Errorf("message = %q, want %q", msg, want)
+	}
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	hub := realtime.NewMemoryHub(1)
+	defer hub.Close()
+	h := NewStreamHandler(hub)
+	h.heartbeat = 10 * time.Millisecond
+	body, closer := openStream(t, streamServer(t, h, 7))
+	defer closer.Close()
+
+	if msg := readMessage(t, body); msg != ": heartbeat\n" {
+		t.Errorf("message = %q, want a heartbeat", msg)
+	}
+}
+
+func TestStreamEndsWhenDropped(t *testing.T) {
+	tests := []struct {
+		name string
+		drop func(hub realtime.Hub)
+	}{
+		{
+			name: "slow consumer",
+			drop: func(hub realtime.Hub) {
+				// More events than fit in the buffer at once; some may be
+				// written before the subscription is dropped
+				for i := 0; i < 1000; i++ {
+					hub.Publish(realtime.Event{Type: realtime.EventReviewCreated})
+				}
+			},
+		},
+		{name: "hub closed", drop: func(hub realtime.Hub) { hub.Close() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := realtime.NewMemoryHub(1)
+			defer hub.Close()
+			body, closer := openStream(t, streamServer(t, NewStreamHandler(hub), 7))
+			defer closer.Close()
+
+			tt.drop(hub)
+			// The handler returns, which ends the response
+			done := make(chan error, 1)
+			go func() {
+				_, err := io.Copy(io.Discard, body)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("stream ended with %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("stream still open after the subscription was dropped")
+			}
+		})
+	}
+}
@@ -1,81 +1,56 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/respond"
 	"protein-web-backend/internal/service"
 	"protein-web-backend/internal/types"
 )
 
 type UserHandler struct {
 	service service.UserService
+	// tokenTTL is how long the tokens issued at login stay valid
+	tokenTTL time.Duration
 }
 
-func NewUserHandler(s service.UserService) *UserHandler {
-	return &UserHandler{service: s}
+func NewUserHandler(s service.UserService, tokenTTL time.Duration) *UserHandler {
+	return &UserHandler{service: s, tokenTTL: tokenTTL}
 }
 
 func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.service.GetUsers()
+	users, err := h.service.GetUsers(r.Context())
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Internal server error"})
+		respond.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(users)
+	response := make([]types.PublicUser, 0, len(users))
+	for _, user := range users {
+		response = append(response, types.PublicUser{
+			ID:        user.ID,
+			Email:     user.Email,
+			Name:      user.Name,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		})
+	}
+
+	respond.JSON(w, http.StatusOK, response)
 }
 
 func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Method not allowed"})
-		return
-	}
-
 	var req types.RegisterUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Invalid JSON format"})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	if strings.TrimSpace(req.Email) == "" {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Email is required"})
-		return
-	}
-
-	if strings.TrimSpace(req.Password) == "" {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Password is required"})
-		return
-	}
-
-	user, err := h.service.RegisterUser(req.Email, req.Password, req.Name)
+	user, err := h.service.RegisterUser(r.Context(), req.Email, req.Password, req.Name)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "already exists") {
-			w.WriteHeader(http.StatusConflict)
-		} else if strings.Contains(errorMsg, "invalid") || strings.Contains(errorMsg, "required") ||
-			strings.Contains(errorMsg, "must") {
-			w.WriteHeader(http.StatusBadRequest)
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: errorMsg})
+		respond.Error(w, r, err)
 		return
 	}
 
@@ -86,41 +61,18 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		Message: "User registered successfully",
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	respond.JSON(w, http.StatusCreated, response)
 }
 
 func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Method not allowed"})
-		return
-	}
-
 	var req types.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Invalid JSON format"})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	token, user, err := h.service.LoginUser(req.Email, req.Password)
+	token, user, err := h.service.LoginUser(r.Context(), req.Email, req.Password)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "invalid email or password") {
-			w.WriteHeader(http.StatusUnauthorized)
-		} else if strings.Contains(errorMsg, "required") || strings.Contains(errorMsg, "invalid") {
-			w.WriteHeader(http.StatusBadRequest)
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-
-		json.NewEncoder(w).Encode(types.ErrorResponse{Error: errorMsg})
+		respond.Error(w, r, err)
 		return
 	}
 
@@ -131,11 +83,54 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 			Email: user.Email,
 			Name:  user.Name,
 		},
-		ExpiresAt: "24h",
+		ExpiresAt: compactDuration(h.tokenTTL),
 		Message:   "Login successful",
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	respond.JSON(w, http.StatusOK, response)
+}
+
+// compactDuration formats d like time.Duration.String without zero minutes
+// and seconds, e.g. "24h" rather than "24h0m0s", as expires_at always has
+func compactDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// DeleteUser soft-deletes a user (admin only)
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.service.DeleteUser(r.Context(), middleware.UserIDFromContext(r.Context()), id); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreUser undoes a soft delete (admin only)
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.service.RestoreUser(r.Context(), middleware.UserIDFromContext(r.Context()), id); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
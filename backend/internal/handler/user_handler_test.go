@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/service"
+	"protein-web-backend/internal/types"
+)
+
+type loginUserService struct{ service.UserService }
+
+func (loginUserService) LoginUser(ctx context.Context, email, password string) (string, *model.User, error) {
+	return "token", &model.User{ID: 1, Email: email}, nil
+}
+
+func (loginUserService) GetUsers(ctx context.Context) ([]model.User, error) {
+	return []model.User{{ID: 1, Email: "admin@example.com", Role: model.RoleAdmin}}, nil
+}
+
+func TestGetUsersOmitsRole(t *testing.T) {
+	h := NewUserHandler(loginUserService{}, time.Hour)
+	w := httptest.NewRecorder()
+	h.GetUsers(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	var users []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &users); err != nil || len(users) != 1 {
+		t.Fatalf("body = %s (%v)", w.Body.String(), err)
+	}
+	if _, ok := users[0]["role"]; ok {
+		t.Errorf("public user list includes the role: %s", w.Body.String())
+	}
+}
+
+func TestLoginUserReportsTokenTTL(t *testing.T) {
+	tests := []struct {
+		ttl  time.Duration
+		want string
+	}{
+		{ttl: 24 * time.Hour, want: "24h"},
+		{ttl: 15 * time.Minute, want: "15m"},
+		{ttl: 90 * time.Minute, want: "1h30m"},
+		{ttl: time.Hour + 30*time.Second, want: "1h0m30s"},
+		{ttl: 45 * time.Second, want: "45s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			h := NewUserHandler(loginUserService{}, tt.ttl)
+			r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"user@example.com","password":"password123"}`))
+			w := httptest.NewRecorder()
+
+			h.LoginUser(w, r)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+			}
+			var resp types.LoginResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ExpiresAt != tt.want {
+				t.Errorf("expires_at = %q, want %q", resp.ExpiresAt, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+
+	"protein-web-backend/internal/middleware"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
+	"protein-web-backend/internal/service"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r)
+
+	hooks, err := h.webhookService.ListWebhooks(r.Context(), limit, offset)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if hooks == nil {
+		hooks = []*model.Webhook{}
+	}
+
+	respond.JSON(w, http.StatusOK, hooks)
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	hook, err := h.webhookService.CreateWebhook(r.Context(), middleware.UserIDFromContext(r.Context()), &req)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, hook)
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	hook, err := h.webhookService.GetWebhook(r.Context(), id)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, hook)
+}
+
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	var req model.UpdateWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	hook, err := h.webhookService.UpdateWebhook(r.Context(), middleware.UserIDFromContext(r.Context()), id, &req)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, hook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), middleware.UserIDFromContext(r.Context()), id); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+	limit, offset := pagination(r)
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), id, limit, offset)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*model.WebhookDelivery{}
+	}
+
+	respond.JSON(w, http.StatusOK, deliveries)
+}
+
+// PingWebhook sends a webhook.ping event and returns the delivery, which
+// reports whether the receiver accepted it
+func (h *WebhookHandler) PingWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respond.Status(w, r, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	delivery, err := h.webhookService.PingWebhook(r.Context(), id)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, delivery)
+}
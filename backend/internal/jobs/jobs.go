@@ -0,0 +1,72 @@
+// Package jobs runs background work outside of HTTP requests. Jobs are rows
+// of the jobs table: Queue inserts them, and a Worker pool claims due jobs
+// with SELECT ... FOR UPDATE SKIP LOCKED, runs the handler registered for
+// their type and retries failures with exponential backoff until they are
+// dead-lettered. Workers may run inside the API server or in cmd/worker;
+// any number of them can share the table.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// HandlerFunc processes the raw payload of a job
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Registry maps job types to their handlers
+type Registry struct {
+	handlers map[string]HandlerFunc
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]HandlerFunc)}
+}
+
+// Register adds a handler whose payload is decoded into T. A payload that
+// does not decode fails the job permanently, since retrying cannot fix it.
+func Register[T any](r *Registry, jobType string, handle func(ctx context.Context, payload T) error) {
+	if _, ok := r.handlers[jobType]; ok {
+		panic(fmt.Sprintf("jobs: handler for %q registered twice", jobType))
+	}
+	r.handlers[jobType] = func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return Permanent(fmt.Errorf("invalid payload: %w", err))
+		}
+		return handle(ctx, payload)
+	}
+}
+
+type attemptKey struct{}
+
+// Attempt returns which attempt at its job a handler is running, starting
+// at 1
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+func (r *Registry) handler(jobType string) (HandlerFunc, bool) {
+	h, ok := r.handlers[jobType]
+	return h, ok
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; the job is dead-lettered at once
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	type payload struct {
+		ReviewID int `json:"reviewId"`
+	}
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name      string
+		raw       string
+		handleErr error
+		want      int // review id seen by the handler, 0 if not called
+		permanent bool
+		wantErr   bool
+	}{
+		{name: "decoded", raw: `{"reviewId":3}`, want: 3},
+		{name: "handler error is retried", raw: `{"reviewId":3}`, handleErr: errHandler, want: 3, wantErr: true},
+		{name: "wrong type", raw: `{"reviewId":"3"}`, permanent: true, wantErr: true},
+		{name: "not JSON", raw: `reviewId=3`, permanent: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			var got int
+			Register(r, "t", func(ctx context.Context, p payload) error {
+				got = p.ReviewID
+				return tt.handleErr
+			})
+			handle, ok := r.handler("t")
+			if !ok {
+				t.Fatal("handler not registered")
+			}
+
+			err := handle(context.Background(), json.RawMessage(tt.raw))
+			if (err != nil) != tt.wantErr || isPermanent(err) != tt.permanent {
+				t.Errorf("err = %v, want error %v, permanent %v", err, tt.wantErr, tt.permanent)
+			}
+			if got != tt.want {
+				t.Errorf("handler saw review %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	r := NewRegistry()
+	Register(r, "t", func(context.Context, struct{}) error { return nil })
+	defer func() {
+		if recover() == nil {
+			t.Error("second Register did not panic")
+		}
+	}()
+	Register(r, "t", func(context.Context, struct{}) error { return nil })
+}
+
+func TestPermanent(t *testing.T) {
+	base := errors.New("bad")
+	err := Permanent(base)
+	if !isPermanent(err) || !errors.Is(err, base) || err.Error() != "bad" {
+		t.Errorf("Permanent(%v) = %v", base, err)
+	}
+	if isPermanent(base) || isPermanent(nil) {
+		t.Error("plain errors are permanent")
+	}
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// Queue enqueues jobs. It is safe for concurrent use.
+type Queue struct {
+	repo        repository.JobRepository
+	maxAttempts int
+}
+
+// NewQueue returns a queue whose jobs are tried maxAttempts times unless an
+// Option says otherwise
+func NewQueue(repo repository.JobRepository, maxAttempts int) *Queue {
+	return &Queue{repo: repo, maxAttempts: maxAttempts}
+}
+
+type enqueueOptions struct {
+	delay       time.Duration
+	maxAttempts int
+	uniqueKey   *string
+}
+
+type Option func(*enqueueOptions)
+
+// Delay runs the job no earlier than d from now
+func Delay(d time.Duration) Option {
+	return func(o *enqueueOptions) { o.delay = d }
+}
+
+// At runs the job no earlier than t. The delay is taken from the local
+// clock; the database clock decides when it is due.
+func At(t time.Time) Option {
+	return Delay(time.Until(t))
+}
+
+func MaxAttempts(n int) Option {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+// Unique skips the enqueue when a job with the same key exists, including
+// finished jobs that have not been purged yet
+func Unique(key string) Option {
+	return func(o *enqueueOptions) { o.uniqueKey = &key }
+}
+
+// Enqueue adds a job of jobType with payload encoded as JSON. It returns nil
+// and no error when a Unique key is already taken.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) (*model.Job, error) {
+	o := enqueueOptions{maxAttempts: q.maxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s job payload: %w", jobType, err)
+	}
+
+	job := &model.Job{
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: max(o.maxAttempts, 1),
+		UniqueKey:   o.uniqueKey,
+	}
+	inserted, err := q.repo.Enqueue(ctx, job, max(o.delay, 0))
+	if err != nil || !inserted {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// memoryJobRepository keeps jobs like the jobs table does, on a clock the
+// test moves with advance
+type memoryJobRepository struct {
+	mu     sync.Mutex
+	now    time.Time
+	jobs   []*memoryJob
+	delays []time.Duration // passed to Retry
+}
+
+type memoryJob struct {
+	job      model.Job
+	lockedBy string
+}
+
+func newMemoryJobRepository() *memoryJobRepository {
+	return &memoryJobRepository{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (r *memoryJobRepository) advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = r.now.Add(d)
+}
+
+// statuses returns the status of every job, in insertion order
+func (r *memoryJobRepository) statuses() []model.JobStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := make([]model.JobStatus, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		statuses = append(statuses, j.job.Status)
+	}
+	return statuses
+}
+
+func (r *memoryJobRepository) Enqueue(ctx context.Context, job *model.Job, delay time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job.UniqueKey != nil {
+		for _, j := range r.jobs {
+			if j.job.UniqueKey != nil && *j.job.UniqueKey == *job.UniqueKey {
+				return false, nil
+			}
+		}
+	}
+	job.ID = int64(len(r.jobs) + 1)
+	job.Status = model.JobPending
+	stored := *job
+	stored.RunAt = r.now.Add(delay)
+	r.jobs = append(r.jobs, &memoryJob{job: stored})
+	return true, nil
+}
+
+func (r *memoryJobRepository) Claim(ctx context.Context, workerID string, lease time.Duration) (*model.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := make([]*memoryJob, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		active := j.job.Status == model.JobPending || j.job.Status == model.JobRunning
+		if active && !j.job.RunAt.After(r.now) {
+			due = append(due, j)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(due, func(a, b int) bool { return due[a].job.RunAt.Before(due[b].job.RunAt) })
+
+	j := due[0]
+	j.job.Status = model.JobRunning
+	j.job.Attempts++
+	j.job.RunAt = r.now.Add(lease)
+	j.lockedBy = workerID
+	claimed := j.job
+	return &claimed, nil
+}
+
+// held returns the job if workerID still holds its lock
+func (r *memoryJobRepository) held(id int64, workerID string) (*memoryJob, error) {
+	for _, j := range r.jobs {
+		if j.job.ID == id && j.job.Status == model.JobRunning && j.lockedBy == workerID {
+			return j, nil
+		}
+	}
+	return nil, repository.ErrJobLeaseLost
+}
+
+func (r *memoryJobRepository) Complete(ctx context.Context, id int64, workerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, err := r.held(id, workerID)
+	if err != nil {
+		return err
+	}
+	j.job.Status, j.lockedBy = model.JobDone, ""
+	return nil
+}
+
+func (r *memoryJobRepository) Retry(ctx context.Context, id int64, workerID string, delay time.Duration, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, err := r.held(id, workerID)
+	if err != nil {
+		return err
+	}
+	j.job.Status, j.lockedBy, j.job.LastError = model.JobPending, "", &lastError
+	j.job.RunAt = r.now.Add(delay)
+	r.delays = append(r.delays, delay)
+	return nil
+}
+
+func (r *memoryJobRepository) Bury(ctx context.Context, id int64, workerID string, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, err := r.held(id, workerID)
+	if err != nil {
+		return err
+	}
+	j.job.Status, j.lockedBy, j.job.LastError = model.JobDead, "", &lastError
+	return nil
+}
+
+func (r *memoryJobRepository) PurgeFinished(ctx context.Context, age time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func TestQueueEnqueue(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+	q := NewQueue(repo, 5)
+
+	job, err := q.Enqueue(ctx, "send", map[string]int{"id": 1}, Delay(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.MaxAttempts != 5 || string(job.Payload) != `{"id":1}` {
+		t.Errorf("job = %+v", job)
+	}
+	if got := repo.jobs[0].job.RunAt.Sub(repo.now); got != time.Minute {
+		t.Errorf("job runs in %v, want 1m", got)
+	}
+
+	job, err = q.Enqueue(ctx, "send", nil, MaxAttempts(0), Delay(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.MaxAttempts != 1 || !repo.jobs[1].job.RunAt.Equal(repo.now) {
+		t.Errorf("job = %+v, want one attempt due now", repo.jobs[1].job)
+	}
+
+	if _, err := q.Enqueue(ctx, "send", make(chan int)); err == nil {
+		t.Error("Enqueue of a payload that does not encode succeeded")
+	}
+}
+
+func TestQueueEnqueueUnique(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+	q := NewQueue(repo, 1)
+
+	first, err := q.Enqueue(ctx, "send", nil, Unique("k"))
+	if err != nil || first == nil {
+		t.Fatalf("first Enqueue = %v, %v", first, err)
+	}
+	second, err := q.Enqueue(ctx, "send", nil, Unique("k"))
+	if err != nil || second != nil {
+		t.Errorf("second Enqueue = %v, %v, want nil, nil", second, err)
+	}
+	if len(repo.jobs) != 1 {
+		t.Errorf("%d jobs, want 1", len(repo.jobs))
+	}
+}
+
+func TestSchedulesAreEnqueuedOncePerInterval(t *testing.T) {
+	repo := newMemoryJobRepository()
+	q := NewQueue(repo, 1)
+	schedule := Schedule{Name: "purge", Type: "purge", Every: time.Hour, Payload: json.RawMessage(`{}`)}
+
+	// Several processes run the same schedule; each ticks many times
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		w := NewWorker(repo, q, NewRegistry(), WorkerOptions{PollInterval: time.Millisecond})
+		w.Schedule(schedule)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runSchedules(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// The slot only changes when the run crosses an hour boundary
+	if n := len(repo.jobs); n < 1 || n > 2 {
+		t.Fatalf("%d scheduled jobs, want 1 per interval", n)
+	}
+	if key := *repo.jobs[0].job.UniqueKey; !strings.HasPrefix(key, "schedule:purge:") {
+		t.Errorf("unique key = %q", key)
+	}
+}
@@ -0,0 +1,256 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+	"protein-web-backend/internal/tracing"
+)
+
+// finishTimeout bounds recording a job's result, which happens even when
+// the handler used up its whole lease
+const finishTimeout = 10 * time.Second
+
+type WorkerOptions struct {
+	// Concurrency is the number of jobs run at the same time
+	Concurrency int
+	// PollInterval is how long an idle worker waits before looking again
+	PollInterval time.Duration
+	// Lease is how long a job may run. It is the handler's deadline, and
+	// once it passes another worker may take the job over.
+	Lease time.Duration
+	// The n-th retry waits about BackoffBase * 2^(n-1), at most BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// Schedule enqueues a job every interval. Every worker process may run the
+// same schedules: the run of each interval is enqueued only once.
+type Schedule struct {
+	// Name identifies the schedule in the jobs' unique keys; keep it stable
+	Name    string
+	Type    string
+	Every   time.Duration
+	Payload interface{}
+}
+
+type Worker struct {
+	repo      repository.JobRepository
+	queue     *Queue
+	registry  *Registry
+	opts      WorkerOptions
+	id        string
+	schedules []Schedule
+}
+
+func NewWorker(repo repository.JobRepository, queue *Queue, registry *Registry, opts WorkerOptions) *Worker {
+	return &Worker{repo: repo, queue: queue, registry: registry, opts: opts, id: workerID()}
+}
+
+// Schedule adds a recurring job. Call it before Run.
+func (w *Worker) Schedule(s Schedule) {
+	w.schedules = append(w.schedules, s)
+}
+
+// Run processes jobs until ctx is done. Jobs already claimed then run to
+// completion (or until their lease ends) before Run returns.
+func (w *Worker) Run(ctx context.Context) {
+	logging.FromContext(ctx).Info("job worker started", "worker_id", w.id, "concurrency", w.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.poll(ctx)
+		}()
+	}
+	if len(w.schedules) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runSchedules(ctx)
+		}()
+	}
+	wg.Wait()
+
+	logging.FromContext(ctx).Info("job worker stopped", "worker_id", w.id)
+}
+
+// Start runs the worker in the background. The returned function stops it
+// and waits up to timeout for running jobs, reporting whether they finished.
+// Jobs that did not are retried by a worker once their lease expires.
+func (w *Worker) Start(ctx context.Context) (stop func(timeout time.Duration) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	return func(timeout time.Duration) bool {
+		cancel()
+		select {
+		case <-done:
+			return true
+		case <-time.After(timeout):
+			return false
+		}
+	}
+}
+
+func (w *Worker) poll(ctx context.Context) {
+	for {
+		job, err := w.repo.Claim(ctx, w.id, w.opts.Lease)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error("failed to claim job", "error", err)
+		}
+		if job != nil {
+			w.process(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.opts.PollInterval):
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *model.Job) {
+	// Shutdown stops claiming, but a claimed job keeps its whole lease
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.opts.Lease)
+	defer cancel()
+	runCtx = context.WithValue(runCtx, attemptKey{}, job.Attempts)
+	runCtx = logging.With(runCtx, "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts)
+	runCtx, span := tracing.Tracer().Start(runCtx, "job "+job.Type,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int64("job.id", job.ID),
+			attribute.String("job.type", job.Type),
+			attribute.Int("job.attempt", job.Attempts),
+		),
+	)
+	defer span.End()
+	logger := logging.FromContext(runCtx)
+
+	start := time.Now()
+	err := w.run(runCtx, job)
+	latency := time.Since(start).Milliseconds()
+
+	finishCtx, cancelFinish := context.WithTimeout(context.WithoutCancel(runCtx), finishTimeout)
+	defer cancelFinish()
+
+	if err == nil {
+		if err := w.repo.Complete(finishCtx, job.ID, w.id); err != nil {
+			logger.Error("failed to complete job", "error", err)
+			return
+		}
+		logger.Info("job completed", "latency_ms", latency)
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "job failed")
+	if isPermanent(err) || job.Attempts >= job.MaxAttempts {
+		if buryErr := w.repo.Bury(finishCtx, job.ID, w.id, err.Error()); buryErr != nil {
+			logger.Error("failed to dead-letter job", "error", buryErr)
+			return
+		}
+		logger.Error("job failed permanently", "error", err, "latency_ms", latency)
+		return
+	}
+
+	delay := w.backoff(job.Attempts)
+	if retryErr := w.repo.Retry(finishCtx, job.ID, w.id, delay, err.Error()); retryErr != nil {
+		logger.Error("failed to reschedule job", "error", retryErr)
+		return
+	}
+	logger.Warn("job failed, will retry", "error", err, "retry_in", delay.String(), "latency_ms", latency)
+}
+
+func (w *Worker) run(ctx context.Context, job *model.Job) (err error) {
+	// Claiming counts an attempt, so a job whose lease keeps expiring (the
+	// worker crashed or the handler hung) runs out of attempts too
+	if job.Attempts > job.MaxAttempts {
+		return Permanent(errors.New("lease expired on the last attempt"))
+	}
+	handle, ok := w.registry.handler(job.Type)
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+	return handle(ctx, job.Payload)
+}
+
+// backoff returns the delay before the retry that follows attempt. Jitter
+// spreads out jobs that failed together, e.g. during an outage.
+func (w *Worker) backoff(attempt int) time.Duration {
+	d := w.opts.BackoffMax
+	if shift := max(attempt-1, 0); shift < 32 {
+		if exp := w.opts.BackoffBase << shift; exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	return d/2 + mathrand.N(d/2+1)
+}
+
+func (w *Worker) runSchedules(ctx context.Context) {
+	enqueued := make([]time.Time, len(w.schedules))
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		for i, s := range w.schedules {
+			slot := now.Truncate(s.Every)
+			if slot.Equal(enqueued[i]) {
+				continue
+			}
+			key := fmt.Sprintf("schedule:%s:%d", s.Name, slot.UnixMilli())
+			if _, err := w.queue.Enqueue(ctx, s.Type, s.Payload, Unique(key)); err != nil {
+				if ctx.Err() == nil {
+					logging.FromContext(ctx).Error("failed to enqueue scheduled job", "schedule", s.Name, "error", err)
+				}
+				continue
+			}
+			enqueued[i] = slot
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// workerID names this process in the locked_by column
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+func testWorker(repo repository.JobRepository, registry *Registry) *Worker {
+	return NewWorker(repo, NewQueue(repo, 3), registry, WorkerOptions{
+		Concurrency:  1,
+		PollInterval: time.Millisecond,
+		Lease:        time.Minute,
+		BackoffBase:  time.Second,
+		BackoffMax:   time.Minute,
+	})
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration // before jitter, which takes off up to half
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 6, want: 32 * time.Second},
+		{attempt: 7, want: time.Minute},
+		{attempt: 40, want: time.Minute},
+	}
+
+	w := testWorker(nil, nil)
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := w.backoff(tt.attempt); got < tt.want/2 || got > tt.want {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+}
+
+func TestWorkerProcess(t *testing.T) {
+	tests := []struct {
+		name        string
+		jobType     string
+		maxAttempts int
+		failures    int // attempts that fail before the handler succeeds
+		handle      func(context.Context, struct{}) error
+		want        model.JobStatus
+		retries     int
+	}{
+		{
+			name:   "success",
+			handle: func(context.Context, struct{}) error { return nil },
+			want:   model.JobDone,
+		},
+		{
+			name:     "retried until it succeeds",
+			failures: 2,
+			want:     model.JobDone,
+			retries:  2,
+		},
+		{
+			name:     "buried at max attempts",
+			failures: 3,
+			want:     model.JobDead,
+			retries:  2,
+		},
+		{
+			name:        "one attempt is not retried",
+			maxAttempts: 1,
+			failures:    1,
+			want:        model.JobDead,
+		},
+		{
+			name:   "permanent errors are not retried",
+			handle: func(context.Context, struct{}) error { return Permanent(errors.New("gone")) },
+			want:   model.JobDead,
+		},
+		{
+			name:    "panics are retried",
+			handle:  func(context.Context, struct{}) error { panic("boom") },
+			want:    model.JobDead,
+			retries: 2,
+		},
+		{
+			name:    "unknown job type",
+			jobType: "unknown",
+			want:    model.JobDead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newMemoryJobRepository()
+			registry := NewRegistry()
+			var attempts []int
+			handle := tt.handle
+			if handle == nil {
+				handle = func(ctx context.Context, _ struct{}) error {
+					if Attempt(ctx) <= tt.failures {
+						return errors.New("temporary")
+					}
+					return nil
+				}
+			}
+			Register(registry, "t", func(ctx context.Context, p struct{}) error {
+				attempts = append(attempts, Attempt(ctx))
+				return handle(ctx, p)
+			})
+			w := testWorker(repo, registry)
+
+			jobType := tt.jobType
+			if jobType == "" {
+				jobType = "t"
+			}
+			opts := []Option{}
+			if tt.maxAttempts > 0 {
+				opts = append(opts, MaxAttempts(tt.maxAttempts))
+			}
+			if _, err := w.queue.Enqueue(ctx, jobType, struct{}{}, opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			// Run the job whenever it is due, like polling workers would
+			for i := 0; i < 10; i++ {
+				job, err := repo.Claim(ctx, w.id, w.opts.Lease)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if job != nil {
+					w.process(ctx, job)
+				}
+				repo.advance(time.Minute)
+			}
+
+			if got := repo.statuses(); !reflect.DeepEqual(got, []model.JobStatus{tt.want}) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+			if len(repo.delays) != tt.retries {
+				t.Errorf("%d retries, want %d", len(repo.delays), tt.retries)
+			}
+			for i, d := range attempts {
+				if d != i+1 {
+					t.Errorf("attempts = %v, want 1, 2, ...", attempts)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestWorkerReclaimsExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+	registry := NewRegistry()
+	Register(registry, "t", func(context.Context, struct{}) error { return nil })
+	crashed := testWorker(repo, registry)
+	w := testWorker(repo, registry)
+
+	if _, err := w.queue.Enqueue(ctx, "t", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := repo.Claim(ctx, crashed.id, time.Minute); job == nil {
+		t.Fatal("no job to claim")
+	}
+
+	// While the lease holds nobody else gets the job
+	repo.advance(30 * time.Second)
+	if job, _ := repo.Claim(ctx, w.id, time.Minute); job != nil {
+		t.Fatalf("claimed a job under lease: %+v", job)
+	}
+
+	repo.advance(time.Minute)
+	job, err := repo.Claim(ctx, w.id, time.Minute)
+	if err != nil || job == nil {
+		t.Fatalf("Claim after the lease expired = %v, %v", job, err)
+	}
+	if job.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", job.Attempts)
+	}
+	w.process(ctx, job)
+	if got := repo.statuses(); got[0] != model.JobDone {
+		t.Errorf("status = %v, want done", got[0])
+	}
+
+	// The worker that lost the job can no longer finish it
+	if err := repo.Complete(ctx, job.ID, crashed.id); !errors.Is(err, repository.ErrJobLeaseLost) {
+		t.Errorf("Complete by the old worker = %v, want ErrJobLeaseLost", err)
+	}
+}
+
+func TestWorkerBuriesJobWhoseLeaseExpiredOnTheLastAttempt(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+	ran := false
+	registry := NewRegistry()
+	Register(registry, "t", func(context.Context, struct{}) error {
+		ran = true
+		return nil
+	})
+	w := testWorker(repo, registry)
+
+	if _, err := w.queue.Enqueue(ctx, "t", struct{}{}, MaxAttempts(2)); err != nil {
+		t.Fatal(err)
+	}
+	// Two workers crash while running the job
+	for _, id := range []string{"a", "b"} {
+		if job, _ := repo.Claim(ctx, id, time.Minute); job == nil {
+			t.Fatal("no job to claim")
+		}
+		repo.advance(2 * time.Minute)
+	}
+
+	job, _ := repo.Claim(ctx, w.id, time.Minute)
+	if job == nil {
+		t.Fatal("no job to claim")
+	}
+	w.process(ctx, job)
+	if ran {
+		t.Error("the handler ran beyond max attempts")
+	}
+	if got := repo.statuses(); got[0] != model.JobDead {
+		t.Errorf("status = %v, want dead", got[0])
+	}
+}
+
+func TestWorkerStartStop(t *testing.T) {
+	repo := newMemoryJobRepository()
+	registry := NewRegistry()
+	done := make(chan struct{})
+	Register(registry, "t", func(context.Context, struct{}) error {
+		close(done)
+		return nil
+	})
+	w := testWorker(repo, registry)
+	if _, err := w.queue.Enqueue(context.Background(), "t", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := w.Start(context.Background())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job did not run")
+	}
+	if !stop(5 * time.Second) {
+		t.Fatal("the worker did not stop")
+	}
+	if got := repo.statuses(); got[0] != model.JobDone {
+		t.Errorf("status = %v, want done", got[0])
+	}
+}
@@ -0,0 +1,33 @@
+package logging
+
+import "context"
+
+type requestKey struct{}
+
+// Request collects details that are only known deep inside the handler
+// chain, such as the authenticated user, for the access log written by the
+// outermost middleware.
+type Request struct {
+	ID     string
+	UserID int
+}
+
+// WithRequest returns a copy of ctx carrying req
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// RequestFromContext returns the request details, or nil outside a request
+func RequestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestKey{}).(*Request)
+	return req
+}
+
+// SetUser records the authenticated user for the access log and adds it to
+// the request logger.
+func SetUser(ctx context.Context, userID int) context.Context {
+	if req := RequestFromContext(ctx); req != nil {
+		req.UserID = userID
+	}
+	return With(ctx, "user_id", userID)
+}
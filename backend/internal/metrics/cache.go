@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"context"
+
+	"protein-web-backend/internal/cache"
+)
+
+// cacheStore counts hits and misses; writes pass through
+type cacheStore struct {
+	cache.Cache
+	metrics *Metrics
+}
+
+func Cache(next cache.Cache, m *Metrics) cache.Cache {
+	return &cacheStore{Cache: next, metrics: m}
+}
+
+func (c *cacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Cache.Get(ctx, key)
+
+	result := "miss"
+	switch {
+	case err != nil:
+		result = "error"
+	case ok:
+		result = "hit"
+	}
+	c.metrics.CacheLookups.WithLabelValues(result).Inc()
+
+	return value, ok, err
+}
@@ -0,0 +1,98 @@
+// Package metrics defines the Prometheus metrics of the server and the
+// decorators that record them around repositories and services.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "protein"
+
+// Metrics owns a registry so that tests and multiple instances never clash
+// with the global default registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	HTTPRequestDuration *prometheus.HistogramVec
+	DBQueryDuration     *prometheus.HistogramVec
+	ReviewsCreated      prometheus.Counter
+	Logins              *prometheus.CounterVec
+	// ReviewImagesAdded counts images attached to new reviews. Images are
+	// submitted as URLs; there is no upload endpoint yet.
+	ReviewImagesAdded prometheus.Counter
+	CacheLookups      *prometheus.CounterVec
+	OutboxDeliveries  *prometheus.CounterVec
+}
+
+// New registers the application metrics along with Go runtime, process and
+// connection pool statistics of the primary and, if not nil, the replica.
+func New(primary, replica *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to serve HTTP requests by route pattern and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		DBQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time spent in repository methods, including all queries they run.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method", "outcome"}),
+		ReviewsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviews_created_total",
+			Help:      "Reviews created.",
+		}),
+		Logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by result (success, failure, error).",
+		}, []string{"result"}),
+		ReviewImagesAdded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "review_images_added_total",
+			Help:      "Images attached to newly created reviews.",
+		}),
+		CacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Response cache reads by result (hit, miss, error).",
+		}, []string{"result"}),
+		OutboxDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbox_deliveries_total",
+			Help:      "Attempts to hand outbox events to a sink by sink, event type and result (success, failure).",
+		}, []string{"sink", "type", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		// open, in-use and idle connections, wait count and wait duration
+		collectors.NewDBStatsCollector(primary, "main"),
+		m.HTTPRequestDuration,
+		m.DBQueryDuration,
+		m.ReviewsCreated,
+		m.Logins,
+		m.ReviewImagesAdded,
+		m.CacheLookups,
+		m.OutboxDeliveries,
+	)
+	if replica != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(replica, "replica"))
+	}
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
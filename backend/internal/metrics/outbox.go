@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"context"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/outbox"
+)
+
+// outboxSink counts deliveries per sink
+type outboxSink struct {
+	outbox.Sink
+	metrics *Metrics
+}
+
+func Sink(next outbox.Sink, m *Metrics) outbox.Sink {
+	return &outboxSink{Sink: next, metrics: m}
+}
+
+func (s *outboxSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	err := s.Sink.Publish(ctx, event)
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	s.metrics.OutboxDeliveries.WithLabelValues(s.Name(), event.Type, result).Inc()
+
+	return err
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// observeQuery records how long a repository method took. Not-found results
+// are expected outcomes, not errors.
+func (m *Metrics) observeQuery(repo, method string, start time.Time, err error) {
+	outcome := "ok"
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrReviewNotFound),
+		errors.Is(err, repository.ErrReviewImageNotFound),
+		errors.Is(err, repository.ErrUserNotFound):
+		outcome = "not_found"
+	case errors.Is(err, repository.ErrReviewModified):
+		outcome = "conflict"
+	default:
+		outcome = "error"
+	}
+	m.DBQueryDuration.WithLabelValues(repo, method, outcome).Observe(time.Since(start).Seconds())
+}
+
+type reviewRepository struct {
+	next    repository.ReviewRepository
+	metrics *Metrics
+}
+
+// ReviewRepository times every call to next
+func ReviewRepository(next repository.ReviewRepository, m *Metrics) repository.ReviewRepository {
+	return &reviewRepository{next: next, metrics: m}
+}
+
+func (r *reviewRepository) observe(method string, start time.Time, err error) {
+	r.metrics.observeQuery("review", method, start, err)
+}
+
+func (r *reviewRepository) Create(ctx context.Context, review *model.Review) error {
+	start := time.Now()
+	err := r.next.Create(ctx, review)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r *reviewRepository) CreateImage(ctx context.Context, image *model.ReviewImage) error {
+	start := time.Now()
+	err := r.next.CreateImage(ctx, image)
+	r.observe("CreateImage", start, err)
+	return err
+}
+
+func (r *reviewRepository) GetByID(ctx context.Context, id int) (*model.Review, error) {
+	start := time.Now()
+	result, err := r.next.GetByID(ctx, id)
+	r.observe("GetByID", start, err)
+	return result, err
+}
+
+func (r *reviewRepository) GetAll(ctx context.Context, viewerID, limit, offset int) ([]*model.Review, error) {
+	start := time.Now()
+	result, err := r.next.GetAll(ctx, viewerID, limit, offset)
+	r.observe("GetAll", start, err)
+	return result, err
+}
+
+func (r *reviewRepository) GetByUserID(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error) {
+	start := time.Now()
+	result, err := r.next.GetByUserID(ctx, userID, viewerID, limit, offset)
+	r.observe("GetByUserID", start, err)
+	return result, err
+}
+
+func (r *reviewRepository) Update(ctx context.Context, review *model.Review, version int) error {
+	start := time.Now()
+	err := r.next.Update(ctx, review, version)
+	r.observe("Update", start, err)
+	return err
+}
+
+func (r *reviewRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	start := time.Now()
+	err := r.next.SetHidden(ctx, id, hidden)
+	r.observe("SetHidden", start, err)
+	return err
+}
+
+func (r *reviewRepository) SoftDelete(ctx context.Context, id, version int) error {
+	start := time.Now()
+	err := r.next.SoftDelete(ctx, id, version)
+	r.observe("SoftDelete", start, err)
+	return err
+}
+
+func (r *reviewRepository) Restore(ctx context.Context, id int) error {
+	start := time.Now()
+	err := r.next.Restore(ctx, id)
+	r.observe("Restore", start, err)
+	return err
+}
+
+func (r *reviewRepository) DeleteImage(ctx context.Context, reviewID, imageID, version int) error {
+	start := time.Now()
+	err := r.next.DeleteImage(ctx, reviewID, imageID, version)
+	r.observe("DeleteImage", start, err)
+	return err
+}
+
+func (r *reviewRepository) ListAllByUserID(ctx context.Context, userID int) ([]*model.Review, error) {
+	start := time.Now()
+	result, err := r.next.ListAllByUserID(ctx, userID)
+	r.observe("ListAllByUserID", start, err)
+	return result, err
+}
+
+type userRepository struct {
+	next    repository.UserRepository
+	metrics *Metrics
+}
+
+// UserRepository times every call to next
+func UserRepository(next repository.UserRepository, m *Metrics) repository.UserRepository {
+	return &userRepository{next: next, metrics: m}
+}
+
+func (r *userRepository) observe(method string, start time.Time, err error) {
+	r.metrics.observeQuery("user", method, start, err)
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]model.User, error) {
+	start := time.Now()
+	result, err := r.next.GetAll(ctx)
+	r.observe("GetAll", start, err)
+	return result, err
+}
+
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	start := time.Now()
+	err := r.next.Create(ctx, user)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	start := time.Now()
+	result, err := r.next.GetByEmail(ctx, email)
+	r.observe("GetByEmail", start, err)
+	return result, err
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	start := time.Now()
+	result, err := r.next.GetByID(ctx, id)
+	r.observe("GetByID", start, err)
+	return result, err
+}
+
+func (r *userRepository) SoftDelete(ctx context.Context, id int) error {
+	start := time.Now()
+	err := r.next.SoftDelete(ctx, id)
+	r.observe("SoftDelete", start, err)
+	return err
+}
+
+func (r *userRepository) Restore(ctx context.Context, id int) error {
+	start := time.Now()
+	err := r.next.Restore(ctx, id)
+	r.observe("Restore", start, err)
+	return err
+}
+
+func (r *userRepository) Erase(ctx context.Context, id int, deleteReviews bool) error {
+	start := time.Now()
+	err := r.next.Erase(ctx, id, deleteReviews)
+	r.observe("Erase", start, err)
+	return err
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/service"
+)
+
+// reviewService counts created reviews; other methods pass through
+type reviewService struct {
+	service.ReviewService
+	metrics *Metrics
+}
+
+func ReviewService(next service.ReviewService, m *Metrics) service.ReviewService {
+	return &reviewService{ReviewService: next, metrics: m}
+}
+
+func (s *reviewService) CreateReview(ctx context.Context, userID int, req *model.CreateReviewRequest) (*model.Review, error) {
+	review, err := s.ReviewService.CreateReview(ctx, userID, req)
+	if err == nil {
+		s.metrics.ReviewsCreated.Inc()
+		s.metrics.ReviewImagesAdded.Add(float64(len(review.Images)))
+	}
+	return review, err
+}
+
+// userService counts login attempts; other methods pass through
+type userService struct {
+	service.UserService
+	metrics *Metrics
+}
+
+func UserService(next service.UserService, m *Metrics) service.UserService {
+	return &userService{UserService: next, metrics: m}
+}
+
+func (s *userService) LoginUser(ctx context.Context, email, password string) (string, *model.User, error) {
+	token, user, err := s.UserService.LoginUser(ctx, email, password)
+
+	result := "success"
+	var unauthorized *service.UnauthorizedError
+	var invalid *service.ValidationError
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorized), errors.As(err, &invalid):
+		result = "failure"
+	default:
+		result = "error"
+	}
+	s.metrics.Logins.WithLabelValues(result).Inc()
+
+	return token, user, err
+}
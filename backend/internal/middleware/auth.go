@@ -2,68 +2,107 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	"protein-web-backend/internal/auth"
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "userID"
+const (
+	UserIDKey   contextKey = "userID"
+	UserRoleKey contextKey = "userRole"
+)
 
-// AuthMiddleware validates JWT tokens and adds user ID to context
-func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Get token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-			return
-		}
+var errMissingAuthHeader = errors.New("Missing authorization header")
+
+// Auth holds the authentication middleware. Its methods share the token
+// verifier so that every route checks tokens against the same secret.
+type Auth struct {
+	tokens *auth.Tokens
+}
+
+func NewAuth(tokens *auth.Tokens) *Auth {
+	return &Auth{tokens: tokens}
+}
 
-		// Check if it's a Bearer token
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+// Required validates JWT tokens and adds user ID to context
+func (a *Auth) Required(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := a.authenticate(r)
+		if err != nil {
+			respond.Status(w, r, http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		tokenString := parts[1]
-
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+// Optional adds the user ID to the context when a valid token is sent, and
+// lets anonymous requests through unchanged.
+func (a *Auth) Optional(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := a.authenticate(r)
+		if errors.Is(err, errMissingAuthHeader) {
+			next.ServeHTTP(w, r)
 			return
 		}
-
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+		if err != nil {
+			respond.Status(w, r, http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		// Get user ID from claims
-		userIDFloat, ok := claims["user_id"].(float64)
-		if !ok {
-			http.Error(w, "Invalid user ID in token", http.StatusUnauthorized)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Admin requires a valid token belonging to an admin user
+func (a *Auth) Admin(next http.Handler) http.Handler {
+	return a.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if role, _ := r.Context().Value(UserRoleKey).(string); role != model.RoleAdmin {
+			respond.Status(w, r, http.StatusForbidden, "Admin privileges required")
 			return
 		}
 
-		userID := int(userIDFloat)
+		next.ServeHTTP(w, r)
+	}))
+}
 
-		// Add user ID to context
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// UserIDFromContext returns the authenticated user ID, or 0 for anonymous requests
+func UserIDFromContext(ctx context.Context) int {
+	userID, _ := ctx.Value(UserIDKey).(int)
+	return userID
+}
+
+func (a *Auth) authenticate(r *http.Request) (context.Context, error) {
+	// Get token from Authorization header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, errMissingAuthHeader
+	}
+
+	// Check if it's a Bearer token
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, errors.New("Invalid authorization header format")
+	}
+
+	claims, err := a.tokens.Parse(parts[1])
+	if err != nil {
+		return nil, err
 	}
-}
\ No newline at end of file
+
+	// Add user ID and role to context
+	ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+	// Let the repositories keep this user's reads consistent with their writes
+	ctx = db.WithUser(ctx, claims.UserID)
+	return logging.SetUser(ctx, claims.UserID), nil
+}
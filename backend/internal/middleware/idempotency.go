@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/respond"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodyBytes matches the handlers' limit on JSON bodies
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+// IdempotencyStore keeps the keys and responses of idempotent requests. It
+// is implemented by repository.IdempotencyRepository.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, userID int, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, userID int, key string, status int, header http.Header, body []byte) error
+	Release(ctx context.Context, userID int, key string) error
+}
+
+// Idempotency lets clients retry a request safely by sending an
+// Idempotency-Key header. The first request with a key runs normally and its
+// response is stored for ttl; retries with the same key and the same method,
+// path and body get that response again, marked with Idempotent-Replayed.
+// Reusing a key for a different request, or while the first one is still
+// running, is a conflict. Server errors are not stored, so the retry runs
+// the request again. A reservation whose request never finished is handed to
+// a retry after lockTimeout.
+//
+// Keys are scoped to the authenticated user, so the middleware goes after
+// authentication; anonymous requests share one scope. Requests without the
+// header are passed through unchanged.
+func Idempotency(store IdempotencyStore, ttl, lockTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				respond.Status(w, r, http.StatusBadRequest, fmt.Sprintf("Idempotency-Key must not exceed %d characters", maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodyBytes+1))
+			if err != nil {
+				respond.Status(w, r, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			if len(body) > maxIdempotentBodyBytes {
+				respond.Status(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not exceed %d bytes", maxIdempotentBodyBytes))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := r.Context()
+			userID := UserIDFromContext(ctx)
+			fingerprint := requestFingerprint(r, body)
+
+			record, reserved, err := store.Reserve(ctx, userID, key, fingerprint, ttl, lockTimeout)
+			if err != nil {
+				respond.Error(w, r, err)
+				return
+			}
+			if !reserved {
+				switch {
+				case record.Fingerprint != fingerprint:
+					respond.Status(w, r, http.StatusConflict, "Idempotency-Key has already been used with a different request")
+				case record.StatusCode == 0:
+					respond.Status(w, r, http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+				default:
+					replay(w, record)
+				}
+				return
+			}
+
+			before := w.Header().Clone()
+			buf := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+
+			// The client may be gone already, but its retry should still
+			// find the response
+			storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			if buf.status >= http.StatusInternalServerError {
+				err = store.Release(storeCtx, userID, key)
+			} else {
+				err = store.Complete(storeCtx, userID, key, buf.status, handlerHeader(before, w.Header()), buf.body.Bytes())
+			}
+			if err != nil {
+				logging.FromContext(ctx).Error("failed to store idempotent response", "key", key, "error", err)
+			}
+		})
+	}
+}
+
+// requestFingerprint identifies what a key was first used for
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// handlerHeader returns the headers the handler set. Those added by outer
+// middleware, such as X-Request-ID, describe this request rather than the
+// stored response.
+func handlerHeader(before, after http.Header) http.Header {
+	header := http.Header{}
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			header[name] = values
+		}
+	}
+	return header
+}
+
+func replay(w http.ResponseWriter, record *model.IdempotencyRecord) {
+	h := w.Header()
+	for name, values := range record.Header {
+		h[name] = values
+	}
+	h.Set("Idempotent-Replayed", "true")
+	h.Set("Content-Length", strconv.Itoa(len(record.Body)))
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
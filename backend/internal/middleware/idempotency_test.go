@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+)
+
+// memoryIdempotencyStore keeps records like the repository does, keyed by
+// user and key
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*model.IdempotencyRecord)}
+}
+
+func storeKey(userID int, key string) string {
+	return fmt.Sprintf("%d:%s", userID, key)
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, userID int, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[storeKey(userID, key)]; ok {
+		copied := *record
+		return &copied, false, nil
+	}
+	s.records[storeKey(userID, key)] = &model.IdempotencyRecord{UserID: userID, Key: key, Fingerprint: fingerprint}
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, userID int, key string, status int, header http.Header, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := s.records[storeKey(userID, key)]
+	record.StatusCode, record.Header, record.Body = status, header, body
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, userID int, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, storeKey(userID, key))
+	return nil
+}
+
+// creating answers 201 with the request body, or 500 for "fail"
+type creating struct {
+	calls atomic.Int32
+}
+
+func (h *creating) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls.Add(1)
+	body, _ := io.ReadAll(r.Body)
+	if string(body) == "fail" {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/things/1")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(body)
+}
+
+type idempotentRequest struct {
+	key    string
+	userID int
+	body   string
+
+	status   int
+	response string // expected body; empty skips the check
+	replayed bool
+}
+
+func sendIdempotent(h http.Handler, req idempotentRequest) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(req.body))
+	if req.key != "" {
+		r.Header.Set("Idempotency-Key", req.key)
+	}
+	if req.userID != 0 {
+		r = r.WithContext(context.WithValue(r.Context(), UserIDKey, req.userID))
+	}
+	w := httptest.NewRecorder()
+	// Outer middleware such as RequestID sets headers before the handler runs
+	w.Header().Set("X-Request-ID", "request")
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []idempotentRequest
+		calls    int32
+	}{
+		{
+			name: "without a key every request runs",
+			requests: []idempotentRequest{
+				{body: `{"a":1}`, status: 201},
+				{body: `{"a":1}`, status: 201},
+			},
+			calls: 2,
+		},
+		{
+			name: "a retry replays the stored response",
+			requests: []idempotentRequest{
+				{key: "k", userID: 1, body: `{"a":1}`, status: 201, response: `{"a":1}`},
+				{key: "k", userID: 1, body: `{"a":1}`, status: 201, response: `{"a":1}`, replayed: true},
+			},
+			calls: 1,
+		},
+		{
+			name: "a key reused with a different body conflicts",
+			requests: []idempotentRequest{
+				{key: "k", userID: 1, body: `{"a":1}`, status: 201},
+				{key: "k", userID: 1, body: `{"a":2}`, status: 409},
+			},
+			calls: 1,
+		},
+		{
+			name: "keys are scoped to the user",
+			requests: []idempotentRequest{
+				{key: "k", userID: 1, body: `{"a":1}`, status: 201},
+				{key: "k", userID: 2, body: `{"a":2}`, status: 201},
+			},
+			calls: 2,
+		},
+		{
+			name: "server errors are not stored",
+			requests: []idempotentRequest{
+				{key: "k", userID: 1, body: "fail", status: 500},
+				{key: "k", userID: 1, body: "fail", status: 500},
+			},
+			calls: 2,
+		},
+		{
+			name: "an overlong key is rejected",
+			requests: []idempotentRequest{
+				{key: strings.Repeat("k", 256), userID: 1, body: `{"a":1}`, status: 400},
+			},
+			calls: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &creating{}
+			h := Idempotency(newMemoryIdempotencyStore(), time.Hour, time.Minute)(next)
+
+			for i, req := range tt.requests {
+				w := sendIdempotent(h, req)
+				if w.Code != req.status {
+					t.Fatalf("request %d: status = %d, want %d; body: %s", i, w.Code, req.status, w.Body.String())
+				}
+				if req.response != "" && w.Body.String() != req.response {
+					t.Errorf("request %d: body = %q, want %q", i, w.Body.String(), req.response)
+				}
+				if got := w.Header().Get("Idempotent-Replayed") == "true"; got != req.replayed {
+					t.Errorf("request %d: replayed = %v, want %v", i, got, req.replayed)
+				}
+				if req.replayed && w.Header().Get("Location") != "/things/1" {
+					t.Errorf("request %d: the handler's headers were not replayed: %v", i, w.Header())
+				}
+			}
+			if got := next.calls.Load(); got != tt.calls {
+				t.Errorf("handler ran %d times, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyDoesNotStoreOuterHeaders(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	h := Idempotency(store, time.Hour, time.Minute)(&creating{})
+
+	sendIdempotent(h, idempotentRequest{key: "k", userID: 1, body: `{"a":1}`})
+
+	record := store.records[storeKey(1, "k")]
+	if record.Header.Get("X-Request-ID") != "" {
+		t.Errorf("stored header includes X-Request-ID: %v", record.Header)
+	}
+	if record.Header.Get("Location") != "/things/1" {
+		t.Errorf("stored header lacks Location: %v", record.Header)
+	}
+}
+
+func TestIdempotencyConflictsWhileInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	next := &creating{}
+	blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		next.ServeHTTP(w, r)
+	})
+	h := Idempotency(newMemoryIdempotencyStore(), time.Hour, time.Minute)(blocking)
+	req := idempotentRequest{key: "k", userID: 1, body: `{"a":1}`}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- sendIdempotent(h, req) }()
+	<-started
+
+	if w := sendIdempotent(h, req); w.Code != http.StatusConflict {
+		t.Fatalf("concurrent retry: status = %d, want 409", w.Code)
+	}
+
+	close(release)
+	if w := <-first; w.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d, want 201", w.Code)
+	}
+	if w := sendIdempotent(h, req); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry after completion: status = %d, replayed = %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if got := next.calls.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics observes the duration of each request in histogram, labelled with
+// the method, route pattern and status code. Like AccessLog it must wrap the
+// router directly. Paths that match no route share the "unmatched" label so
+// that scanners cannot create unbounded label values.
+func Metrics(histogram *prometheus.HistogramVec) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
+
+			_, route, ok := strings.Cut(r.Pattern, " ")
+			if !ok {
+				route = "unmatched"
+			}
+			histogram.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var versionedPath = regexp.MustCompile(`^/v[0-9]+(/|$)`)
+
+// UnversionedAlias keeps pre-versioning URLs working. Requests under prefix
+// that do not name a version (e.g. /api/reviews) are served by version
+// (e.g. /api/v1/reviews), and the response announces the deprecation with
+// Deprecation (RFC 9745), Sunset (RFC 8594) and a successor-version Link.
+func UnversionedAlias(prefix, version string, deprecatedAt, sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+			if !ok || !strings.HasPrefix(rest, "/") || versionedPath.MatchString(rest) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			target := prefix + "/" + version + rest
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, target))
+
+			alias := r.Clone(r.Context())
+			alias.URL.Path = target
+			alias.URL.RawPath = ""
+			alias.RequestURI = alias.URL.RequestURI()
+			next.ServeHTTP(w, alias)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEntityUser        = "user"
+	AuditEntityReview      = "review"
+	AuditEntityReviewImage = "review_image"
+	AuditEntityWebhook     = "webhook"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionHide    = "hide"
+	AuditActionUnhide  = "unhide"
+	AuditActionErase   = "erase"
+)
+
+// AuditLogEntry records a mutation. ActorID is nil for changes made by the
+// system itself, such as automatic hiding.
+type AuditLogEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    *int            `json:"actorId"`
+	EntityType string          `json:"entityType"`
+	EntityID   int             `json:"entityId"`
+	Action     string          `json:"action"`
+	Diff       json.RawMessage `json:"diff"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   int
+	ActorID    int
+}
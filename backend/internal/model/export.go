@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// UserDataExport is everything the service stores about one user
+type UserDataExport struct {
+	ExportedAt time.Time `json:"exportedAt"`
+	Account    *User     `json:"account"`
+	Reviews    []*Review `json:"reviews"`
+	Reports    []*Report `json:"reports"`
+}
@@ -0,0 +1,17 @@
+package model
+
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// Readiness is the result of the readiness probe. Checks maps each
+// dependency to "ok" or a short description of the failure.
+type Readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (r *Readiness) Ready() bool {
+	return r.Status == HealthOK
+}
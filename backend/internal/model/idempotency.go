@@ -0,0 +1,20 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is a request made with an Idempotency-Key and, once it
+// finished, its response
+type IdempotencyRecord struct {
+	UserID      int
+	Key         string
+	Fingerprint string
+	// StatusCode is 0 while the request is in progress
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ModerationResult records what the content filters did with a submission.
+// ReviewID is nil when the submission was rejected.
+type ModerationResult struct {
+	ID           int             `json:"id"`
+	ReviewID     *int            `json:"reviewId,omitempty"`
+	UserID       int             `json:"userId"`
+	Action       string          `json:"action"`
+	OriginalText string          `json:"originalText"`
+	Decisions    json.RawMessage `json:"decisions"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain event types recorded in the outbox
+const (
+	EventReviewCreated = "review.created"
+	EventReviewUpdated = "review.updated"
+	EventReviewDeleted = "review.deleted"
+)
+
+const AggregateReview = "review"
+
+// OutboxEvent is a domain event waiting to be published. Key identifies it
+// across redeliveries; consumers use it to discard duplicates.
+type OutboxEvent struct {
+	ID            int64           `json:"-"`
+	Key           string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   int             `json:"aggregateId"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Attempts      int             `json:"-"`
+	// DeliveredTo lists the sinks that already received the event
+	DeliveredTo []string `json:"-"`
+}
+
+// ReviewEventPayload is the payload of review events. Hidden reviews are
+// only visible to their author and admins.
+type ReviewEventPayload struct {
+	ReviewID int  `json:"reviewId"`
+	UserID   int  `json:"userId"`
+	Hidden   bool `json:"hidden"`
+}
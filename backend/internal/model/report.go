@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHateSpeech     ReportReason = "hate_speech"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonInappropriate  ReportReason = "inappropriate"
+	ReportReasonOther          ReportReason = "other"
+)
+
+// Valid reports whether the reason is one of the known reason codes
+func (r ReportReason) Valid() bool {
+	switch r {
+	case ReportReasonSpam, ReportReasonHarassment, ReportReasonHateSpeech,
+		ReportReasonMisinformation, ReportReasonInappropriate, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusDismissed ReportStatus = "dismissed"
+	ReportStatusActioned  ReportStatus = "actioned"
+)
+
+type Report struct {
+	ID         int          `json:"id"`
+	ReviewID   int          `json:"reviewId"`
+	ReporterID int          `json:"reporterId"`
+	Reason     ReportReason `json:"reason"`
+	Details    *string      `json:"details,omitempty"`
+	Status     ReportStatus `json:"status"`
+	ResolvedBy *int         `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time   `json:"resolvedAt,omitempty"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	Review     *Review      `json:"review,omitempty"`
+}
+
+type CreateReportRequest struct {
+	Reason  ReportReason `json:"reason" validate:"required,oneof=spam harassment hate_speech misinformation inappropriate other"`
+	Details string       `json:"details" validate:"max=1000"`
+}
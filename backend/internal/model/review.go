@@ -11,9 +11,13 @@ type Review struct {
 	ProteinPerServing string         `json:"proteinPerServing"`
 	PricePerServing  string         `json:"pricePerServing"`
 	Comment          string         `json:"comment"`
+	HiddenAt         *time.Time     `json:"hiddenAt,omitempty"`
+	// Version is incremented by every change to the review
+	Version          int            `json:"version"`
 	Images           []ReviewImage  `json:"images,omitempty"`
 	CreatedAt        time.Time      `json:"postedAt"`
 	UpdatedAt        time.Time      `json:"updatedAt"`
+	DeletedAt        *time.Time     `json:"deletedAt,omitempty"`
 }
 
 type ReviewImage struct {
@@ -25,10 +29,18 @@ type ReviewImage struct {
 }
 
 type CreateReviewRequest struct {
-	ProteinPerServing string   `json:"proteinPerServing" validate:"required"`
-	PricePerServing  string   `json:"pricePerServing" validate:"required"`
-	Comment          string   `json:"comment" validate:"required"`
-	Images           []string `json:"images"`
+	ProteinPerServing string   `json:"proteinPerServing" validate:"required,max=50"`
+	PricePerServing  string   `json:"pricePerServing" validate:"required,max=50"`
+	Comment          string   `json:"comment" validate:"required,max=2000"`
+	Images           []string `json:"images" validate:"max=5,dive,required,url,max=500"`
+}
+
+// UpdateReviewRequest changes the given fields of a review; omitted fields
+// keep their value
+type UpdateReviewRequest struct {
+	ProteinPerServing *string `json:"proteinPerServing" validate:"required,max=50"`
+	PricePerServing   *string `json:"pricePerServing" validate:"required,max=50"`
+	Comment           *string `json:"comment" validate:"required,max=2000"`
 }
 
 type ReviewResponse struct {
@@ -39,6 +51,8 @@ type ReviewResponse struct {
 	ProteinPerServing string         `json:"proteinPerServing"`
 	PricePerServing  string         `json:"pricePerServing"`
 	Comment          string         `json:"comment"`
+	Hidden           bool           `json:"hidden,omitempty"`
+	Version          int            `json:"version"`
 }
 
 type UserResponse struct {
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// EventWebhookPing is sent by the admin API to test a webhook. It is not
+// recorded in the outbox.
+const EventWebhookPing = "webhook.ping"
+
+// WebhookEventTypes are the outbox events webhooks may subscribe to
+var WebhookEventTypes = []string{EventReviewCreated, EventReviewUpdated, EventReviewDeleted}
+
+type Webhook struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries. It is only returned when the webhook is
+	// created or its secret is rotated.
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"eventTypes"`
+	Active     bool     `json:"active"`
+	// ConsecutiveFailures counts failed deliveries since the last success
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	DisabledAt          *time.Time `json:"disabledAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+// Subscribes reports whether the webhook receives events of eventType
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records one attempt to deliver an event
+type WebhookDelivery struct {
+	ID         int64     `json:"id"`
+	WebhookID  int       `json:"webhookId"`
+	EventID    string    `json:"eventId"`
+	EventType  string    `json:"eventType"`
+	Attempt    int       `json:"attempt"`
+	Success    bool      `json:"success"`
+	StatusCode *int      `json:"statusCode,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	DurationMs int       `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// WebhookJob is the payload of a job that delivers one event to one webhook
+type WebhookJob struct {
+	WebhookID int          `json:"webhookId"`
+	Event     *OutboxEvent `json:"event"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url,max=2048"`
+	// Secret is generated when empty
+	Secret     string   `json:"secret" validate:"min=16,max=255"`
+	EventTypes []string `json:"eventTypes" validate:"required,dive,oneof=review.created review.updated review.deleted"`
+}
+
+// UpdateWebhookRequest changes the fields that are present. Setting active
+// to true re-enables a webhook that was disabled after repeated failures.
+type UpdateWebhookRequest struct {
+	URL          *string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes   *[]string `json:"eventTypes" validate:"required,dive,oneof=review.created review.updated review.deleted"`
+	Active       *bool     `json:"active"`
+	RotateSecret bool      `json:"rotateSecret"`
+}
@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// BannedWordFilter matches a list of words after Japanese-aware normalization
+type BannedWordFilter struct {
+	action Action
+	words  [][]rune
+}
+
+func NewBannedWordFilter(words []string, action Action) *BannedWordFilter {
+	f := &BannedWordFilter{action: action}
+	for _, w := range words {
+		if n := normalize(w); len(n.runes) > 0 {
+			f.words = append(f.words, n.runes)
+		}
+	}
+	return f
+}
+
+// LoadWordList reads one word per line, ignoring blank lines and lines
+// starting with '#'.
+func LoadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+func (f *BannedWordFilter) Name() string {
+	return "banned_words"
+}
+
+func (f *BannedWordFilter) Apply(text string) (string, *Decision) {
+	if len(f.words) == 0 {
+		return text, nil
+	}
+
+	normalized := normalize(text)
+	original := []rune(text)
+
+	var spans [][2]int
+	var matches []string
+	for _, word := range f.words {
+		for i := 0; i+len(word) <= len(normalized.runes); i++ {
+			if !hasPrefixRunes(normalized.runes[i:], word) {
+				continue
+			}
+			span := normalized.originalSpan(i, i+len(word))
+			spans = append(spans, span)
+			matches = append(matches, string(original[span[0]:span[1]]))
+			i += len(word) - 1
+		}
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	return maskSpans(text, spans), newDecision(f.action, "contains banned words", matches)
+}
+
+func hasPrefixRunes(s, prefix []rune) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
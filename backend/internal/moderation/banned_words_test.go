@@ -0,0 +1,63 @@
+package moderation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBannedWordFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string // text after masking
+		matches []string
+	}{
+		{name: "clean text", text: "プロテインおいしい", want: "プロテインおいしい"},
+		{name: "exact word", text: "この味はばか", want: "この味は**", matches: []string{"ばか"}},
+		{name: "katakana", text: "バカな味", want: "**な味", matches: []string{"バカ"}},
+		{name: "half-width kana", text: "ﾊﾞｶな味", want: "***な味", matches: []string{"ﾊﾞｶ"}},
+		{name: "full-width latin", text: "so ＢＡＤ", want: "so ***", matches: []string{"ＢＡＤ"}},
+		{name: "split by spaces", text: "b a d taste", want: "***** taste", matches: []string{"b a d"}},
+		{name: "several matches", text: "bad, bad", want: "***, ***", matches: []string{"bad", "bad"}},
+	}
+
+	f := NewBannedWordFilter([]string{"ばか", "BAD", "  "}, ActionMask)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, decision := f.Apply(tt.text)
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+			if tt.matches == nil {
+				if decision != nil {
+					t.Errorf("decision = %+v, want none", decision)
+				}
+				return
+			}
+			if decision == nil || decision.action != ActionMask || !reflect.DeepEqual(decision.Matches, tt.matches) {
+				t.Errorf("decision = %+v, want mask of %q", decision, tt.matches)
+			}
+		})
+	}
+}
+
+func TestLoadWordList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# comment\nばか\n\n  bad  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := LoadWordList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ばか", "bad"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("words = %q, want %q", words, want)
+	}
+
+	if _, err := LoadWordList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadWordList of a missing file succeeded")
+	}
+}
@@ -0,0 +1,126 @@
+package moderation
+
+import "strings"
+
+// Action is what a filter decided to do with a piece of text. Actions are
+// ordered by severity so that a chain can report the strongest one.
+type Action int
+
+const (
+	ActionAllow Action = iota
+	ActionMask
+	ActionFlag
+	ActionReject
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionMask:
+		return "mask"
+	case ActionFlag:
+		return "flag"
+	case ActionReject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// ParseAction converts a configuration value such as "mask" into an Action
+func ParseAction(s string) (Action, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "allow":
+		return ActionAllow, true
+	case "mask":
+		return ActionMask, true
+	case "flag":
+		return ActionFlag, true
+	case "reject":
+		return ActionReject, true
+	}
+	return ActionAllow, false
+}
+
+// Decision is the result of running a single filter
+type Decision struct {
+	Filter  string   `json:"filter"`
+	Action  string   `json:"action"`
+	Reason  string   `json:"reason"`
+	Matches []string `json:"matches,omitempty"`
+	action  Action
+}
+
+// Filter inspects text. When it masks, it returns the rewritten text;
+// otherwise it returns the input unchanged.
+type Filter interface {
+	Name() string
+	Apply(text string) (string, *Decision)
+}
+
+// Outcome is the combined result of a Chain
+type Outcome struct {
+	Action    Action
+	Text      string
+	Decisions []Decision
+}
+
+// Reasons returns the human readable reasons of every filter that fired
+func (o *Outcome) Reasons() []string {
+	reasons := make([]string, 0, len(o.Decisions))
+	for _, d := range o.Decisions {
+		reasons = append(reasons, d.Reason)
+	}
+	return reasons
+}
+
+// Chain runs filters in order, feeding each the text produced by the previous
+// one. It stops at the first rejection.
+type Chain struct {
+	filters []Filter
+}
+
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+func (c *Chain) Run(text string) *Outcome {
+	outcome := &Outcome{Action: ActionAllow, Text: text}
+	for _, f := range c.filters {
+		masked, decision := f.Apply(outcome.Text)
+		if decision == nil || decision.action == ActionAllow {
+			continue
+		}
+		decision.Filter = f.Name()
+		decision.Action = decision.action.String()
+		outcome.Decisions = append(outcome.Decisions, *decision)
+
+		if decision.action > outcome.Action {
+			outcome.Action = decision.action
+		}
+		if decision.action == ActionMask {
+			outcome.Text = masked
+		}
+		if decision.action == ActionReject {
+			break
+		}
+	}
+	return outcome
+}
+
+func newDecision(action Action, reason string, matches []string) *Decision {
+	return &Decision{action: action, Reason: reason, Matches: matches}
+}
+
+// maskSpans replaces the runes in each [start, end) span with '*'
+func maskSpans(text string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	for _, span := range spans {
+		for i := span[0]; i < span[1] && i < len(runes); i++ {
+			runes[i] = '*'
+		}
+	}
+	return string(runes)
+}
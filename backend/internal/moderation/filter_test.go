@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Action
+		ok   bool
+	}{
+		{in: "allow", want: ActionAllow, ok: true},
+		{in: " Mask ", want: ActionMask, ok: true},
+		{in: "FLAG", want: ActionFlag, ok: true},
+		{in: "reject", want: ActionReject, ok: true},
+		{in: "block"},
+		{in: ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseAction(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseAction(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		chain   *Chain
+		text    string
+		action  Action
+		want    string
+		filters []string // filters that fired, in order
+	}{
+		{
+			name:   "nothing fires",
+			chain:  NewChain(NewBannedWordFilter([]string{"bad"}, ActionReject), NewLinkFilter(ActionMask, nil)),
+			text:   "おいしい",
+			action: ActionAllow,
+			want:   "おいしい",
+		},
+		{
+			name:    "masks feed the next filter",
+			chain:   NewChain(NewBannedWordFilter([]string{"bad"}, ActionMask), NewLinkFilter(ActionFlag, nil)),
+			text:    "bad taste, see cheap.shop",
+			action:  ActionFlag,
+			want:    "*** taste, see cheap.shop",
+			filters: []string{"banned_words", "links"},
+		},
+		{
+			name:    "a flag does not rewrite the text",
+			chain:   NewChain(NewPhoneNumberFilter(ActionFlag)),
+			text:    "090-1234-5678",
+			action:  ActionFlag,
+			want:    "090-1234-5678",
+			filters: []string{"phone_numbers"},
+		},
+		{
+			name:    "reject stops the chain",
+			chain:   NewChain(NewBannedWordFilter([]string{"bad"}, ActionReject), NewLinkFilter(ActionMask, nil)),
+			text:    "bad cheap.shop",
+			action:  ActionReject,
+			want:    "bad cheap.shop",
+			filters: []string{"banned_words"},
+		},
+		{
+			name:   "allow decisions are dropped",
+			chain:  NewChain(NewLinkFilter(ActionAllow, nil)),
+			text:   "cheap.shop",
+			action: ActionAllow,
+			want:   "cheap.shop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := tt.chain.Run(tt.text)
+			if outcome.Action != tt.action || outcome.Text != tt.want {
+				t.Errorf("outcome = %v %q, want %v %q", outcome.Action, outcome.Text, tt.action, tt.want)
+			}
+			var filters []string
+			for _, d := range outcome.Decisions {
+				if d.Action != d.action.String() {
+					t.Errorf("decision %s has action %q, want %q", d.Filter, d.Action, d.action)
+				}
+				filters = append(filters, d.Filter)
+			}
+			if !reflect.DeepEqual(filters, tt.filters) {
+				t.Errorf("filters = %q, want %q", filters, tt.filters)
+			}
+			if len(outcome.Reasons()) != len(tt.filters) {
+				t.Errorf("reasons = %q", outcome.Reasons())
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package moderation
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	linkPattern  = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"]+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|jp|shop|store|site|online|xyz|io|me|ly|to|gl|link|click)\b(?:/[^\s<>"]*)?`)
+	phonePattern = regexp.MustCompile(`(?:\+81[\s-]?|\b0)\d{1,4}[\s-]?\(?\d{1,4}\)?[\s-]?\d{3,4}\b`)
+)
+
+// LinkFilter detects URLs and bare domains, including ones typed with
+// full-width characters. Hosts under an allowed domain are ignored.
+type LinkFilter struct {
+	action         Action
+	allowedDomains []string
+}
+
+func NewLinkFilter(action Action, allowedDomains []string) *LinkFilter {
+	f := &LinkFilter{action: action}
+	for _, d := range allowedDomains {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			f.allowedDomains = append(f.allowedDomains, d)
+		}
+	}
+	return f
+}
+
+func (f *LinkFilter) Name() string {
+	return "links"
+}
+
+func (f *LinkFilter) Apply(text string) (string, *Decision) {
+	folded := string(foldWidth(text))
+	original := []rune(text)
+
+	var spans [][2]int
+	var matches []string
+	for _, span := range runeSpans(folded, linkPattern.FindAllStringIndex(folded, -1)) {
+		if f.allowed(string(foldWidth(string(original[span[0]:span[1]])))) {
+			continue
+		}
+		spans = append(spans, span)
+		matches = append(matches, string(original[span[0]:span[1]]))
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	return maskSpans(text, spans), newDecision(f.action, "contains links", matches)
+}
+
+func (f *LinkFilter) allowed(link string) bool {
+	if len(f.allowedDomains) == 0 {
+		return false
+	}
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range f.allowedDomains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// PhoneNumberFilter detects Japanese and international phone numbers
+type PhoneNumberFilter struct {
+	action Action
+}
+
+func NewPhoneNumberFilter(action Action) *PhoneNumberFilter {
+	return &PhoneNumberFilter{action: action}
+}
+
+func (f *PhoneNumberFilter) Name() string {
+	return "phone_numbers"
+}
+
+func (f *PhoneNumberFilter) Apply(text string) (string, *Decision) {
+	folded := string(foldWidth(text))
+	original := []rune(text)
+
+	var spans [][2]int
+	var matches []string
+	for _, span := range runeSpans(folded, phonePattern.FindAllStringIndex(folded, -1)) {
+		if countDigits(folded, span) < 10 {
+			continue
+		}
+		spans = append(spans, span)
+		matches = append(matches, string(original[span[0]:span[1]]))
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	return maskSpans(text, spans), newDecision(f.action, "contains phone numbers", matches)
+}
+
+func countDigits(s string, span [2]int) int {
+	n := 0
+	for _, r := range []rune(s)[span[0]:span[1]] {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		text    string
+		want    string
+		matches []string
+	}{
+		{name: "no link", text: "毎日飲んでいます", want: "毎日飲んでいます"},
+		{name: "https URL", text: "see https://spam.example/x now", want: "see ********************** now", matches: []string{"https://spam.example/x"}},
+		{name: "www prefix", text: "www.example.org", want: "***************", matches: []string{"www.example.org"}},
+		{name: "bare domain", text: "buy at cheap.shop!", want: "buy at **********!", matches: []string{"cheap.shop"}},
+		{name: "full-width domain", text: "ｃｈｅａｐ．ｃｏｍ", want: "*********", matches: []string{"ｃｈｅａｐ．ｃｏｍ"}},
+		{name: "unknown TLD is not a link", text: "version 1.2.beta", want: "version 1.2.beta"},
+		{name: "allowed domain", allowed: []string{"Example.com"}, text: "https://example.com/a", want: "https://example.com/a"},
+		{name: "allowed subdomain", allowed: []string{"example.com"}, text: "shop.example.com", want: "shop.example.com"},
+		{name: "suffix of another domain", allowed: []string{"example.com"}, text: "badexample.com", want: "**************", matches: []string{"badexample.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, decision := NewLinkFilter(ActionMask, tt.allowed).Apply(tt.text)
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+			var matches []string
+			if decision != nil {
+				matches = decision.Matches
+			}
+			if !reflect.DeepEqual(matches, tt.matches) {
+				t.Errorf("matches = %q, want %q", matches, tt.matches)
+			}
+		})
+	}
+}
+
+func TestPhoneNumberFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		matches []string
+	}{
+		{name: "mobile with hyphens", text: "連絡は090-1234-5678へ", matches: []string{"090-1234-5678"}},
+		{name: "landline", text: "03-1234-5678", matches: []string{"03-1234-5678"}},
+		{name: "international", text: "+81 90 1234 5678", matches: []string{"+81 90 1234 5678"}},
+		{name: "full-width digits", text: "０９０１２３４５６７８", matches: []string{"０９０１２３４５６７８"}},
+		{name: "too few digits", text: "03-123-456"},
+		{name: "amounts are not numbers", text: "30g 120kcal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, decision := NewPhoneNumberFilter(ActionFlag).Apply(tt.text)
+			var matches []string
+			if decision != nil {
+				matches = decision.Matches
+			}
+			if !reflect.DeepEqual(matches, tt.matches) {
+				t.Errorf("matches = %q, want %q", matches, tt.matches)
+			}
+		})
+	}
+}
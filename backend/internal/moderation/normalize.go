@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	combiningVoicedMark     = '゙'
+	combiningSemiVoicedMark = '゚'
+	katakanaStart           = 'ァ'
+	katakanaEnd             = 'ヶ'
+	katakanaToHiragana      = 0x60
+)
+
+// normalizedText is text reduced to a canonical form for matching, with each
+// rune remembering which rune of the original text it came from so that
+// matches can be masked in the original.
+type normalizedText struct {
+	runes  []rune
+	origin []int
+}
+
+// normalize folds full-width and half-width forms (NFKC), lower-cases, maps
+// katakana to hiragana and drops whitespace, punctuation and symbols, so that
+// "ＢＡＤ", "b a d" and "ﾊﾞｶ"/"バカ"/"ばか" all compare equal.
+func normalize(text string) normalizedText {
+	var out normalizedText
+	for i, r := range []rune(text) {
+		for _, n := range norm.NFKC.String(string(r)) {
+			if n == combiningVoicedMark || n == combiningSemiVoicedMark {
+				// Half-width kana carry the (semi-)voiced mark as a separate rune
+				if last := len(out.runes) - 1; last >= 0 {
+					composed := norm.NFC.String(string(out.runes[last]) + string(n))
+					if utf8.RuneCountInString(composed) == 1 {
+						out.runes[last], _ = utf8.DecodeRuneInString(composed)
+					}
+				}
+				continue
+			}
+
+			n = unicode.ToLower(n)
+			if n >= katakanaStart && n <= katakanaEnd {
+				n -= katakanaToHiragana
+			}
+			if !unicode.IsLetter(n) && !unicode.IsNumber(n) {
+				continue
+			}
+
+			out.runes = append(out.runes, n)
+			out.origin = append(out.origin, i)
+		}
+	}
+	return out
+}
+
+// originalSpan converts a [start, end) range of normalized runes into the
+// corresponding rune range of the original text.
+func (t normalizedText) originalSpan(start, end int) [2]int {
+	return [2]int{t.origin[start], t.origin[end-1] + 1}
+}
+
+// foldWidth applies NFKC rune by rune, keeping a one-to-one rune mapping with
+// the input so regular expression matches can be located in the original.
+func foldWidth(text string) []rune {
+	runes := []rune(text)
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = r
+		if f := norm.NFKC.String(string(r)); utf8.RuneCountInString(f) == 1 {
+			folded[i], _ = utf8.DecodeRuneInString(f)
+		}
+	}
+	return folded
+}
+
+// runeSpans converts byte offsets returned by regexp into rune offsets
+func runeSpans(s string, byteSpans [][]int) [][2]int {
+	spans := make([][2]int, 0, len(byteSpans))
+	for _, span := range byteSpans {
+		start := utf8.RuneCountInString(s[:span[0]])
+		end := start + utf8.RuneCountInString(s[span[0]:span[1]])
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
+}
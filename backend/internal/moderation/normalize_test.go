@@ -0,0 +1,50 @@
+package moderation
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "full-width latin", text: "ＢＡＤ", want: "bad"},
+		{name: "spaces and punctuation", text: "b a-d!", want: "bad"},
+		{name: "katakana to hiragana", text: "バカ", want: "ばか"},
+		{name: "half-width kana with voiced mark", text: "ﾊﾞｶ", want: "ばか"},
+		{name: "half-width kana with semi-voiced mark", text: "ﾊﾟﾝ", want: "ぱん"},
+		{name: "full-width digits", text: "１２３", want: "123"},
+		{name: "kanji unchanged", text: "筋肉 最高", want: "筋肉最高"},
+		{name: "symbols dropped", text: "★☆♪", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(normalize(tt.text).runes); got != tt.want {
+				t.Errorf("normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeOriginalSpan(t *testing.T) {
+	// "ﾊﾞｶ" is four runes; the voiced mark folds into the first
+	n := normalize("xﾊﾞ ｶy")
+	if got := string(n.runes); got != "xばかy" {
+		t.Fatalf("runes = %q", got)
+	}
+	if got := n.originalSpan(1, 3); got != [2]int{1, 5} {
+		t.Errorf("originalSpan(1, 3) = %v, want [1 5]", got)
+	}
+}
+
+func TestFoldWidth(t *testing.T) {
+	text := "ｗｗｗ．ｅｘａｍｐｌｅ．ｃｏｍ"
+	folded := foldWidth(text)
+	if got := string(folded); got != "www.example.com" {
+		t.Errorf("foldWidth = %q", got)
+	}
+	if len(folded) != len([]rune(text)) {
+		t.Errorf("foldWidth changed the rune count from %d to %d", len([]rune(text)), len(folded))
+	}
+}
@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	maxRepeatedRunes  = 8
+	maxRepeatedLines  = 3
+	maxLinks          = 2
+	maxSymbolRatio    = 0.3
+	minSpamSignals    = 2
+	minLengthForRatio = 20
+)
+
+// SpamFilter scores text on simple signals: long runs of a repeated
+// character, the same line pasted several times, many links, and a high
+// proportion of symbols. Text with enough signals triggers the action.
+type SpamFilter struct {
+	action Action
+}
+
+func NewSpamFilter(action Action) *SpamFilter {
+	return &SpamFilter{action: action}
+}
+
+func (f *SpamFilter) Name() string {
+	return "spam"
+}
+
+func (f *SpamFilter) Apply(text string) (string, *Decision) {
+	var signals []string
+
+	if longestRun([]rune(text)) >= maxRepeatedRunes {
+		signals = append(signals, "repeated characters")
+	}
+	if mostRepeatedLine(text) >= maxRepeatedLines {
+		signals = append(signals, "repeated lines")
+	}
+	if folded := string(foldWidth(text)); len(linkPattern.FindAllStringIndex(folded, -1)) >= maxLinks {
+		signals = append(signals, "many links")
+	}
+	if symbolRatio(text) > maxSymbolRatio {
+		signals = append(signals, "mostly symbols")
+	}
+
+	if len(signals) < minSpamSignals {
+		return text, nil
+	}
+	return text, newDecision(f.action, "looks like spam", signals)
+}
+
+func longestRun(runes []rune) int {
+	longest, current := 0, 0
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			current = 0
+			continue
+		}
+		if i > 0 && runes[i-1] == r {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+func mostRepeatedLine(text string) int {
+	counts := make(map[string]int)
+	most := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		counts[line]++
+		if counts[line] > most {
+			most = counts[line]
+		}
+	}
+	return most
+}
+
+func symbolRatio(text string) float64 {
+	total, symbols := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			symbols++
+		}
+	}
+	if total < minLengthForRatio {
+		return 0
+	}
+	return float64(symbols) / float64(total)
+}
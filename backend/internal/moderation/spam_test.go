@@ -0,0 +1,54 @@
+package moderation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpamFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		signals []string // nil when the text is not spam
+	}{
+		{name: "ordinary review", text: "甘さ控えめで飲みやすい。リピートします！"},
+		{name: "one signal is not enough", text: "最高ーーーーーーーーー"},
+		{
+			name:    "repeated characters and lines",
+			text:    strings.Repeat("買ってwwwwwwww\n", 3),
+			signals: []string{"repeated characters", "repeated lines"},
+		},
+		{
+			name:    "links and symbols",
+			text:    "!!! https://a.example/x !!! https://b.example/y ★☆★☆★☆★☆",
+			signals: []string{"many links", "mostly symbols"},
+		},
+		{
+			name:    "spaces break a run",
+			text:    "w w w w w w w w w\nsame\nsame\nsame",
+			signals: nil,
+		},
+		{
+			// Mostly symbols, but too short for the ratio to count
+			name: "short text",
+			text: "!!!!!!!! ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, decision := NewSpamFilter(ActionFlag).Apply(tt.text)
+			if got != tt.text {
+				t.Errorf("spam filter changed the text to %q", got)
+			}
+			var signals []string
+			if decision != nil {
+				signals = decision.Matches
+			}
+			if !reflect.DeepEqual(signals, tt.signals) {
+				t.Errorf("signals = %q, want %q", signals, tt.signals)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"protein-web-backend/internal/logging"
+)
+
+// maxCapturedBody bounds how much of each response is kept for validation.
+// Larger JSON bodies are reported as unchecked rather than buffered.
+const maxCapturedBody = 1 << 20
+
+// Conformance returns middleware that checks every response against the
+// document: the status code must be documented for the matched operation,
+// the content type must be one the operation declares, and JSON bodies must
+// match their schema. Violations are logged, never sent to the client.
+//
+// The route tests in cmd/server apply the same checks to every operation;
+// this catches what they miss in real traffic. It buffers a copy of each
+// JSON response, so it is meant for development and CI runs
+// (OPENAPI_VALIDATE_RESPONSES=true) rather than production.
+func (s *Spec) Conformance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// r.Pattern is set by the ServeMux; it is empty for 404 and 405
+		// responses generated by the router itself.
+		_, path, ok := strings.Cut(r.Pattern, " ")
+		if !ok {
+			return
+		}
+		for _, violation := range s.check(r.Method, path, rec.status, rec.Header(), rec.body.Bytes(), rec.truncated) {
+			logging.FromContext(r.Context()).Warn("openapi violation", "method", r.Method, "route", path, "status", rec.status, "violation", violation)
+		}
+	})
+}
+
+// CheckResponse returns how a response of the operation at method and path,
+// a route pattern such as /api/v1/reviews/{id}, deviates from the document.
+// It applies the checks of Conformance and is used by the route tests.
+func (s *Spec) CheckResponse(method, path string, status int, header http.Header, body []byte) []string {
+	return s.check(method, path, status, header, body, false)
+}
+
+func (s *Spec) check(method, path string, status int, header http.Header, body []byte, truncated bool) []string {
+	op := s.operation(method, path)
+	if op == nil {
+		return []string{"operation is not documented"}
+	}
+
+	resp := s.response(op, status)
+	if resp == nil {
+		return []string{"status code is not documented"}
+	}
+
+	content, _ := resp["content"].(map[string]interface{})
+	if len(content) == 0 {
+		if len(body) > 0 {
+			return []string{"response has a body but none is documented"}
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	media, ok := content[mediaType].(map[string]interface{})
+	if !ok {
+		return []string{"content type " + mediaType + " is not documented"}
+	}
+	if !strings.HasSuffix(mediaType, "json") {
+		return nil
+	}
+	if truncated {
+		return []string{"body too large to validate"}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{"body is not valid JSON: " + err.Error()}
+	}
+	return s.validate(media["schema"], value, "$")
+}
+
+// recorder passes the response through while keeping the status code and a
+// copy of JSON bodies.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	truncated   bool
+	wroteHeader bool
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	if strings.Contains(rec.Header().Get("Content-Type"), "json") && !rec.truncated {
+		if rec.body.Len()+len(p) > maxCapturedBody {
+			rec.truncated = true
+			rec.body.Reset()
+		} else {
+			rec.body.Write(p)
+		}
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// the SSE stream needs for flushing.
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
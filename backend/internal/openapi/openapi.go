@@ -0,0 +1,129 @@
+// Package openapi embeds the API description served at /api/openapi.json and
+// checks the running server against it: every registered route must be
+// documented, and responses can optionally be validated against their
+// documented schemas.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"protein-web-backend/internal/router"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// Spec is the parsed API description
+type Spec struct {
+	raw   []byte
+	paths map[string]map[string]interface{}
+	root  map[string]interface{}
+}
+
+// Load parses the embedded document
+func Load() (*Spec, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(document, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi.json: %w", err)
+	}
+
+	paths := make(map[string]map[string]interface{})
+	rawPaths, _ := root["paths"].(map[string]interface{})
+	for path, item := range rawPaths {
+		operations, _ := item.(map[string]interface{})
+		paths[path] = operations
+	}
+
+	return &Spec{raw: document, paths: paths, root: root}, nil
+}
+
+// ServeHTTP serves the document as JSON
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(s.raw)))
+	w.Write(s.raw)
+}
+
+// CheckRoutes returns an error naming every route that has no operation in
+// the document, so the server refuses to start with an out-of-date spec.
+func (s *Spec) CheckRoutes(routes []router.Route) error {
+	var missing []string
+	for _, route := range routes {
+		if s.operation(route.Method, route.Pattern) == nil {
+			missing = append(missing, route.Method+" "+route.Pattern)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from openapi.json: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Operations returns every documented operation as "METHOD /path", sorted
+func (s *Spec) Operations() []string {
+	var operations []string
+	for path, item := range s.paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func (s *Spec) operation(method, path string) map[string]interface{} {
+	op, _ := s.paths[path][strings.ToLower(method)].(map[string]interface{})
+	return op
+}
+
+// response returns the documented response for status, falling back to
+// "default", with any $ref resolved.
+func (s *Spec) response(op map[string]interface{}, status int) map[string]interface{} {
+	responses, _ := op["responses"].(map[string]interface{})
+	resp, ok := responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = responses["default"]
+	}
+	if !ok {
+		return nil
+	}
+	resolved, _ := s.resolve(resp).(map[string]interface{})
+	return resolved
+}
+
+// resolve follows local "#/..." references
+func (s *Spec) resolve(node interface{}) interface{} {
+	for {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return node
+		}
+		node = s.lookup(ref)
+	}
+}
+
+func (s *Spec) lookup(ref string) interface{} {
+	var node interface{} = s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	return node
+}
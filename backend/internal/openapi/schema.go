@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// validate checks a decoded JSON value against a schema. It understands the
+// subset of OpenAPI 3.0 schema keywords that openapi.json uses: $ref, type,
+// nullable, enum, required, properties, additionalProperties, items,
+// maxItems and the date-time format. Each violation is reported with a JSON
+// path such as $.user.name.
+func (s *Spec) validate(schema interface{}, value interface{}, path string) []string {
+	sch, ok := s.resolve(schema).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if value == nil {
+		if nullable, _ := sch["nullable"].(bool); nullable || sch["type"] == nil {
+			return nil
+		}
+		return []string{path + ": must not be null"}
+	}
+
+	if enum, ok := sch["enum"].([]interface{}); ok && !contains(enum, value) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", path, value, enum)}
+	}
+
+	switch sch["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{path + ": must be an object"}
+		}
+		return s.validateObject(sch, obj, path)
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return []string{path + ": must be an array"}
+		}
+		var errs []string
+		if max, ok := sch["maxItems"].(float64); ok && float64(len(arr)) > max {
+			errs = append(errs, fmt.Sprintf("%s: has more than %v items", path, max))
+		}
+		for i, item := range arr {
+			errs = append(errs, s.validate(sch["items"], item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []string{path + ": must be a string"}
+		}
+		if sch["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return []string{path + ": must be an RFC 3339 date-time"}
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return []string{path + ": must be an integer"}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{path + ": must be a number"}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{path + ": must be a boolean"}
+		}
+	}
+	return nil
+}
+
+func (s *Spec) validateObject(sch map[string]interface{}, obj map[string]interface{}, path string) []string {
+	var errs []string
+
+	required, _ := sch["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := obj[name.(string)]; !ok {
+			errs = append(errs, fmt.Sprintf("%s.%s: is required", path, name))
+		}
+	}
+
+	properties, _ := sch["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if prop, ok := properties[key]; ok {
+			errs = append(errs, s.validate(prop, obj[key], path+"."+key)...)
+			continue
+		}
+		switch extra := sch["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				errs = append(errs, fmt.Sprintf("%s.%s: is not documented", path, key))
+			}
+		case map[string]interface{}:
+			errs = append(errs, s.validate(extra, obj[key], path+"."+key)...)
+		}
+	}
+	return errs
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"protein-web-backend/internal/model"
+)
+
+// Commander sends a raw command to a Redis-compatible server; cache.Redis
+// implements it
+type Commander interface {
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// StreamSink appends events to a Redis stream with XADD, trimming it to
+// about maxLen entries. Consumer groups read the stream on their own and
+// deduplicate by the id field, which holds the event key.
+type StreamSink struct {
+	redis  Commander
+	stream string
+	maxLen int
+}
+
+func NewStreamSink(redis Commander, stream string, maxLen int) *StreamSink {
+	return &StreamSink{redis: redis, stream: stream, maxLen: maxLen}
+}
+
+func (s *StreamSink) Name() string {
+	return "stream:" + s.stream
+}
+
+func (s *StreamSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	args := []interface{}{"XADD", s.stream}
+	if s.maxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.Itoa(s.maxLen))
+	}
+	args = append(args, "*", "id", event.Key, "type", event.Type, "event", data)
+	_, err = s.redis.Do(ctx, args...)
+	return err
+}
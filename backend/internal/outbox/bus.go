@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// Handler consumes an event
+type Handler func(ctx context.Context, event *model.OutboxEvent) error
+
+// Bus is a Sink that calls the handlers subscribed to the event type in this
+// process. When one handler fails the whole event is retried, so handlers
+// that must not repeat themselves are wrapped with Idempotent.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe calls handle for every event of eventType
+func (b *Bus) Subscribe(eventType string, handle Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handle)
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+func (b *Bus) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	b.mu.RLock()
+	handlers := b.handlers[event.Type]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handle := range handlers {
+		if err := handle(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Idempotent runs handle at most once per event key for consumer. The key
+// is recorded after handle succeeds, so a crash in between repeats it;
+// handlers that write to the database can close that gap by running the
+// returned Handler inside a repository.Transactor, since the record then
+// commits with their writes.
+func Idempotent(repo repository.OutboxRepository, consumer string, handle Handler) Handler {
+	return func(ctx context.Context, event *model.OutboxEvent) error {
+		done, err := repo.Processed(ctx, consumer, event.Key)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if err := handle(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", consumer, err)
+		}
+		return repo.MarkProcessed(ctx, consumer, event.Key)
+	}
+}
@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/model"
+)
+
+func TestBusPublish(t *testing.T) {
+	errFirst := errors.New("first failed")
+	errThird := errors.New("third failed")
+
+	var called []string
+	handler := func(name string, err error) Handler {
+		return func(ctx context.Context, event *model.OutboxEvent) error {
+			called = append(called, name)
+			return err
+		}
+	}
+	bus := NewBus()
+	bus.Subscribe(model.EventReviewCreated, handler("first", errFirst))
+	bus.Subscribe(model.EventReviewCreated, handler("second", nil))
+	bus.Subscribe(model.EventReviewCreated, handler("third", errThird))
+	bus.Subscribe(model.EventReviewDeleted, handler("other", nil))
+
+	err := bus.Publish(context.Background(), &model.OutboxEvent{Type: model.EventReviewCreated})
+	if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+		t.Errorf("err = %v, want both handler errors", err)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(called, want) {
+		t.Errorf("called = %q, want %q", called, want)
+	}
+
+	called = nil
+	if err := bus.Publish(context.Background(), &model.OutboxEvent{Type: "unknown"}); err != nil || called != nil {
+		t.Errorf("event without subscribers: err = %v, called = %q", err, called)
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	ctx := context.Background()
+	errHandler := errors.New("handler failed")
+	repo := newMemoryOutboxRepository()
+
+	calls := 0
+	fail := true
+	handle := Idempotent(repo, "search", func(ctx context.Context, event *model.OutboxEvent) error {
+		calls++
+		if fail {
+			return errHandler
+		}
+		return nil
+	})
+	event := &model.OutboxEvent{Key: "k1"}
+
+	// A failure is not recorded, so the redelivery runs the handler again
+	err := handle(ctx, event)
+	if !errors.Is(err, errHandler) || err.Error() != "search: handler failed" {
+		t.Errorf("err = %v, want the handler error with the consumer name", err)
+	}
+	fail = false
+	for i := 0; i < 3; i++ {
+		if err := handle(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+
+	// Consumers keep separate records
+	other := Idempotent(repo, "mail", func(ctx context.Context, event *model.OutboxEvent) error {
+		calls++
+		return nil
+	})
+	if err := other(ctx, event); err != nil || calls != 3 {
+		t.Errorf("another consumer: err = %v, calls = %d", err, calls)
+	}
+
+	// Without the record the event cannot be checked, so it is not handled
+	repo.err = errors.New("database down")
+	if err := handle(ctx, &model.OutboxEvent{Key: "k2"}); !errors.Is(err, repo.err) || calls != 3 {
+		t.Errorf("err = %v, calls = %d, want the repository error and no call", err, calls)
+	}
+}
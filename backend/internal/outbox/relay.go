@@ -0,0 +1,216 @@
+// Package outbox publishes domain events recorded in the outbox table.
+// Services add an event in the same transaction as the change it
+// describes, so an event exists exactly when the change committed. A Relay
+// then claims unpublished events and hands them to every Sink (the
+// in-process Bus, a webhook, a message broker) until each one has accepted
+// it or the event runs out of attempts and is dead-lettered. Delivery is at
+// least once: a sink may see an event again after a
+// failure or a crash, and consumers discard duplicates by the event key.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+	"protein-web-backend/internal/tracing"
+)
+
+// Sink receives published events. Publish must be safe to repeat with the
+// same event.
+type Sink interface {
+	// Name identifies the sink in the delivery log; keep it stable
+	Name() string
+	Publish(ctx context.Context, event *model.OutboxEvent) error
+}
+
+// finishTimeout bounds recording the outcome of a delivery
+const finishTimeout = 10 * time.Second
+
+type RelayOptions struct {
+	// BatchSize is the number of events claimed at once
+	BatchSize int
+	// PollInterval is how long the relay waits when no event is due
+	PollInterval time.Duration
+	// Lease is how long a claimed batch may take before another relay may
+	// claim its events again
+	Lease time.Duration
+	// MaxAttempts is how often an event is tried before it is dead-lettered.
+	// Claiming counts an attempt, so an event whose lease keeps expiring
+	// runs out of attempts too.
+	MaxAttempts int
+	// The n-th retry waits about BackoffBase * 2^(n-1), at most BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+type Relay struct {
+	repo  repository.OutboxRepository
+	sinks []Sink
+	opts  RelayOptions
+}
+
+func NewRelay(repo repository.OutboxRepository, sinks []Sink, opts RelayOptions) *Relay {
+	return &Relay{repo: repo, sinks: sinks, opts: opts}
+}
+
+// Run relays events until ctx is done. The batch in progress is finished
+// (or its lease ends) before Run returns.
+func (r *Relay) Run(ctx context.Context) {
+	names := make([]string, len(r.sinks))
+	for i, sink := range r.sinks {
+		names[i] = sink.Name()
+	}
+	logging.FromContext(ctx).Info("outbox relay started", "sinks", names)
+
+	for {
+		events, err := r.repo.Claim(ctx, r.opts.BatchSize, r.opts.Lease)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error("failed to claim outbox events", "error", err)
+		}
+		if len(events) > 0 {
+			r.publishBatch(ctx, events)
+		}
+		// A full batch suggests a backlog; keep going without waiting
+		if len(events) == r.opts.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			logging.FromContext(ctx).Info("outbox relay stopped")
+			return
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// Start runs the relay in the background. The returned function stops it
+// and waits up to timeout for the current batch, reporting whether it
+// finished. Events of an unfinished batch are relayed again once their
+// lease expires.
+func (r *Relay) Start(ctx context.Context) (stop func(timeout time.Duration) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	return func(timeout time.Duration) bool {
+		cancel()
+		select {
+		case <-done:
+			return true
+		case <-time.After(timeout):
+			return false
+		}
+	}
+}
+
+func (r *Relay) publishBatch(ctx context.Context, events []*model.OutboxEvent) {
+	// Shutdown stops claiming, but a claimed batch keeps its whole lease
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.opts.Lease)
+	defer cancel()
+	for _, event := range events {
+		r.publish(ctx, event)
+	}
+}
+
+// publish hands event to the sinks that have not accepted it yet
+func (r *Relay) publish(ctx context.Context, event *model.OutboxEvent) {
+	ctx = logging.With(ctx, "event_id", event.Key, "event_type", event.Type, "attempt", event.Attempts)
+	ctx, span := tracing.Tracer().Start(ctx, "outbox publish "+event.Type,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("event.id", event.Key),
+			attribute.String("event.type", event.Type),
+			attribute.Int("event.attempt", event.Attempts),
+		),
+	)
+	defer span.End()
+	logger := logging.FromContext(ctx)
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	if event.Attempts > r.opts.MaxAttempts {
+		r.bury(finishCtx, event, errors.New("lease expired on the last attempt"))
+		return
+	}
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if slices.Contains(event.DeliveredTo, sink.Name()) {
+			continue
+		}
+		if err := r.deliver(ctx, sink, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		// Remember the sink so that a retry does not repeat it
+		if err := r.repo.MarkDelivered(ctx, event.ID, sink.Name()); err != nil {
+			logger.Error("failed to record outbox delivery", "sink", sink.Name(), "error", err)
+		}
+	}
+
+	if len(errs) == 0 {
+		if err := r.repo.MarkPublished(finishCtx, event.ID); err != nil {
+			logger.Error("failed to mark outbox event published", "error", err)
+		}
+		return
+	}
+
+	err := errors.Join(errs...)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "publish failed")
+	if event.Attempts >= r.opts.MaxAttempts {
+		r.bury(finishCtx, event, err)
+		return
+	}
+	delay := r.backoff(event.Attempts)
+	if rescheduleErr := r.repo.Reschedule(finishCtx, event.ID, delay, err.Error()); rescheduleErr != nil {
+		logger.Error("failed to reschedule outbox event", "error", rescheduleErr)
+		return
+	}
+	logger.Warn("outbox event not delivered, will retry", "error", err, "retry_in", delay.String())
+}
+
+// bury dead-letters event; sinks that have not accepted it never will
+func (r *Relay) bury(ctx context.Context, event *model.OutboxEvent, err error) {
+	logger := logging.FromContext(ctx)
+	if buryErr := r.repo.Bury(ctx, event.ID, err.Error()); buryErr != nil {
+		logger.Error("failed to dead-letter outbox event", "error", buryErr)
+		return
+	}
+	logger.Error("outbox event not delivered, giving up", "error", err)
+}
+
+func (r *Relay) deliver(ctx context.Context, sink Sink, event *model.OutboxEvent) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("sink panicked: %v", p)
+		}
+	}()
+	return sink.Publish(ctx, event)
+}
+
+// backoff returns the delay before the retry that follows attempt
+func (r *Relay) backoff(attempt int) time.Duration {
+	d := r.opts.BackoffMax
+	if shift := max(attempt-1, 0); shift < 32 {
+		if exp := r.opts.BackoffBase << shift; exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	return d/2 + mathrand.N(d/2+1)
+}
@@ -0,0 +1,332 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+)
+
+// memoryOutboxRepository keeps events like the outbox tables do, on a clock
+// the test moves with advance
+type memoryOutboxRepository struct {
+	mu        sync.Mutex
+	now       time.Time
+	events    []*memoryEvent
+	delays    []time.Duration // passed to Reschedule
+	processed map[string]bool // consumer + "/" + key
+	err       error           // returned by Processed and MarkProcessed
+}
+
+type memoryEvent struct {
+	event     model.OutboxEvent
+	nextAt    time.Time
+	published bool
+	dead      bool
+	lastError string
+}
+
+func newMemoryOutboxRepository() *memoryOutboxRepository {
+	return &memoryOutboxRepository{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), processed: make(map[string]bool)}
+}
+
+func (r *memoryOutboxRepository) advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = r.now.Add(d)
+}
+
+func (r *memoryOutboxRepository) get(id int64) *memoryEvent {
+	return r.events[id-1]
+}
+
+func (r *memoryOutboxRepository) Add(ctx context.Context, event *model.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, &memoryEvent{event: *event, nextAt: r.now})
+	return nil
+}
+
+func (r *memoryOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*model.OutboxEvent
+	for _, e := range r.events {
+		if len(claimed) == limit {
+			break
+		}
+		if e.published || e.dead || e.nextAt.After(r.now) {
+			continue
+		}
+		e.event.Attempts++
+		e.nextAt = r.now.Add(lease)
+		event := e.event
+		event.DeliveredTo = slices.Clone(e.event.DeliveredTo)
+		claimed = append(claimed, &event)
+	}
+	return claimed, nil
+}
+
+func (r *memoryOutboxRepository) MarkDelivered(ctx context.Context, id int64, sink string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e := r.get(id); !slices.Contains(e.event.DeliveredTo, sink) {
+		e.event.DeliveredTo = append(e.event.DeliveredTo, sink)
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.get(id).published = true
+	return nil
+}
+
+func (r *memoryOutboxRepository) Reschedule(ctx context.Context, id int64, delay time.Duration, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.get(id)
+	e.nextAt, e.lastError = r.now.Add(delay), lastError
+	r.delays = append(r.delays, delay)
+	return nil
+}
+
+func (r *memoryOutboxRepository) Bury(ctx context.Context, id int64, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.get(id)
+	e.dead, e.lastError = true, lastError
+	return nil
+}
+
+func (r *memoryOutboxRepository) PurgePublished(ctx context.Context, age time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryOutboxRepository) Processed(ctx context.Context, consumer, key string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.processed[consumer+"/"+key], r.err
+}
+
+func (r *memoryOutboxRepository) MarkProcessed(ctx context.Context, consumer, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.processed[consumer+"/"+key] = true
+	return nil
+}
+
+// fakeSink fails the first failures calls to Publish, and panics instead
+// when panics is set
+type fakeSink struct {
+	name     string
+	failures int
+	panics   bool
+	calls    int
+}
+
+func (s *fakeSink) Name() string { return s.name }
+
+func (s *fakeSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	s.calls++
+	if s.calls > s.failures {
+		return nil
+	}
+	if s.panics {
+		panic("sink broke")
+	}
+	return errors.New("sink unavailable")
+}
+
+var testRelayOptions = RelayOptions{
+	BatchSize:    10,
+	PollInterval: time.Millisecond,
+	Lease:        time.Minute,
+	MaxAttempts:  3,
+	BackoffBase:  time.Second,
+	BackoffMax:   time.Minute,
+}
+
+func TestRelayPublish(t *testing.T) {
+	tests := []struct {
+		name        string
+		deliveredTo []string // before the first attempt
+		sinks       []*fakeSink
+		wantCalls   []int
+		published   bool
+		dead        bool
+		retries     int
+	}{
+		{
+			name:      "every sink accepts",
+			sinks:     []*fakeSink{{name: "a"}, {name: "b"}},
+			wantCalls: []int{1, 1},
+			published: true,
+		},
+		{
+			name:        "sinks that received the event are skipped",
+			deliveredTo: []string{"a"},
+			sinks:       []*fakeSink{{name: "a"}, {name: "b"}},
+			wantCalls:   []int{0, 1},
+			published:   true,
+		},
+		{
+			name:      "a retry only goes to the sink that failed",
+			sinks:     []*fakeSink{{name: "a"}, {name: "b", failures: 2}},
+			wantCalls: []int{1, 3},
+			published: true,
+			retries:   2,
+		},
+		{
+			name:      "dead-lettered at max attempts",
+			sinks:     []*fakeSink{{name: "a"}, {name: "b", failures: 10}},
+			wantCalls: []int{1, 3},
+			dead:      true,
+			retries:   2,
+		},
+		{
+			name:      "a panicking sink is retried",
+			sinks:     []*fakeSink{{name: "a", failures: 1, panics: true}},
+			wantCalls: []int{2},
+			published: true,
+			retries:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newMemoryOutboxRepository()
+			sinks := make([]Sink, len(tt.sinks))
+			for i, s := range tt.sinks {
+				sinks[i] = s
+			}
+			relay := NewRelay(repo, sinks, testRelayOptions)
+
+			event := &model.OutboxEvent{Key: "k", Type: model.EventReviewCreated, DeliveredTo: tt.deliveredTo}
+			if err := repo.Add(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+			// Relay whatever is due until the backoff has certainly passed
+			for i := 0; i < 10; i++ {
+				events, _ := repo.Claim(ctx, testRelayOptions.BatchSize, testRelayOptions.Lease)
+				relay.publishBatch(ctx, events)
+				repo.advance(time.Minute)
+			}
+
+			calls := make([]int, len(tt.sinks))
+			for i, s := range tt.sinks {
+				calls[i] = s.calls
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			stored := repo.get(event.ID)
+			if stored.published != tt.published || stored.dead != tt.dead {
+				t.Errorf("published %v, dead %v, want %v, %v", stored.published, stored.dead, tt.published, tt.dead)
+			}
+			if tt.dead && stored.lastError == "" {
+				t.Error("dead event has no last error")
+			}
+			if len(repo.delays) != tt.retries {
+				t.Errorf("%d retries, want %d", len(repo.delays), tt.retries)
+			}
+		})
+	}
+}
+
+func TestRelayBuriesEventWhoseLeaseExpiredOnTheLastAttempt(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryOutboxRepository()
+	sink := &fakeSink{name: "a"}
+	relay := NewRelay(repo, []Sink{sink}, testRelayOptions)
+	if err := repo.Add(ctx, &model.OutboxEvent{Key: "k"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relays claim the event and crash before they finish
+	for i := 0; i < testRelayOptions.MaxAttempts; i++ {
+		if events, _ := repo.Claim(ctx, 1, time.Minute); len(events) != 1 {
+			t.Fatal("no event to claim")
+		}
+		repo.advance(2 * time.Minute)
+	}
+
+	events, _ := repo.Claim(ctx, 1, time.Minute)
+	relay.publishBatch(ctx, events)
+	if sink.calls != 0 {
+		t.Errorf("the sink was called beyond max attempts")
+	}
+	if !repo.get(1).dead {
+		t.Error("the event was not dead-lettered")
+	}
+	if events, _ := repo.Claim(ctx, 1, time.Minute); len(events) != 0 {
+		t.Error("a dead event was claimed")
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration // before jitter, which takes off up to half
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 7, want: time.Minute},
+		{attempt: 100, want: time.Minute},
+	}
+
+	relay := NewRelay(nil, nil, testRelayOptions)
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := relay.backoff(tt.attempt); got < tt.want/2 || got > tt.want {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+}
+
+// channelSink passes every event to a channel
+type channelSink chan *model.OutboxEvent
+
+func (s channelSink) Name() string { return "channel" }
+
+func (s channelSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	s <- event
+	return nil
+}
+
+func TestRelayStartStop(t *testing.T) {
+	repo := newMemoryOutboxRepository()
+	if err := repo.Add(context.Background(), &model.OutboxEvent{Key: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	sink := make(channelSink, 1)
+
+	stop := NewRelay(repo, []Sink{sink}, testRelayOptions).Start(context.Background())
+	select {
+	case event := <-sink:
+		if event.Key != "k" {
+			t.Errorf("event = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event was not relayed")
+	}
+	if !stop(5 * time.Second) {
+		t.Fatal("the relay did not stop")
+	}
+	if !repo.get(1).published {
+		t.Error("the event was not marked published")
+	}
+}
@@ -0,0 +1,202 @@
+package realtime
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type EventType string
+
+// EventReviewCreated is the only event published so far. Add a type here
+// together with the code that publishes it.
+const EventReviewCreated EventType = "review.created"
+
+// DefaultBufferSize is the number of events a subscriber may fall behind
+// before it is disconnected as a slow consumer.
+const DefaultBufferSize = 32
+
+var (
+	ErrSlowConsumer = errors.New("subscriber could not keep up with the event stream")
+	ErrHubClosed    = errors.New("event hub closed")
+)
+
+// Event is a message delivered to connected clients.
+// If UserIDs is empty the event is broadcast to every subscriber.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	Data      interface{} `json:"data"`
+	UserIDs   []int       `json:"-"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// ReviewEvent is the payload of EventReviewCreated
+type ReviewEvent struct {
+	ReviewID int `json:"reviewId"`
+	UserID   int `json:"userId"`
+}
+
+// Publisher is the side of the hub used by services to emit events
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Subscription is a single client's view of the event stream
+type Subscription interface {
+	Events() <-chan Event
+	// Done is closed when the subscription ends, either by Close or because
+	// the hub dropped it; Err reports why.
+	Done() <-chan struct{}
+	Err() error
+	Close()
+}
+
+// Hub fans events out to subscribers. The in-memory implementation only
+// reaches clients connected to this process; a broker-backed Hub can replace
+// it when running several instances.
+type Hub interface {
+	Publisher
+	Subscribe(userID int) Subscription
+	Close()
+}
+
+type memoryHub struct {
+	mu          sync.RWMutex
+	subscribers map[*subscription]struct{}
+	bufferSize  int
+	nextID      atomic.Uint64
+	closed      bool
+}
+
+// NewMemoryHub creates an in-process Hub
+func NewMemoryHub(bufferSize int) Hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &memoryHub{
+		subscribers: make(map[*subscription]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+func (h *memoryHub) Publish(event Event) {
+	event.ID = strconv.FormatUint(h.nextID.Add(1), 10)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	h.mu.RLock()
+	var slow []*subscription
+	for sub := range h.subscribers {
+		if !event.isFor(sub.userID) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	// Backpressure: a subscriber whose buffer is full is disconnected so that
+	// one stalled client cannot block delivery to everyone else.
+	for _, sub := range slow {
+		h.remove(sub, ErrSlowConsumer)
+	}
+}
+
+func (h *memoryHub) Subscribe(userID int) Subscription {
+	sub := &subscription{
+		hub:    h,
+		userID: userID,
+		events: make(chan Event, h.bufferSize),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		sub.finish(ErrHubClosed)
+		return sub
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (h *memoryHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for sub := range h.subscribers {
+		delete(h.subscribers, sub)
+		sub.finish(ErrHubClosed)
+	}
+}
+
+func (h *memoryHub) remove(sub *subscription, reason error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	sub.finish(reason)
+}
+
+func (e Event) isFor(userID int) bool {
+	if len(e.UserIDs) == 0 {
+		return true
+	}
+	for _, id := range e.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type subscription struct {
+	hub    *memoryHub
+	userID int
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+func (s *subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+func (s *subscription) Close() {
+	s.hub.remove(s, nil)
+}
+
+// finish must be called with the hub lock held or before the subscription is
+// registered, so that it never races with Publish sending on events.
+func (s *subscription) finish(reason error) {
+	s.once.Do(func() {
+		s.err = reason
+		close(s.done)
+	})
+}
@@ -0,0 +1,116 @@
+package realtime
+
+import (
+	"errors"
+	"testing"
+)
+
+// received drains the events buffered for sub
+func received(sub Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func ended(sub Subscription) bool {
+	select {
+	case <-sub.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewMemoryHub(4)
+	defer hub.Close()
+	alice, bob := hub.Subscribe(1), hub.Subscribe(2)
+
+	hub.Publish(Event{Type: EventReviewCreated})
+	hub.Publish(Event{Type: EventReviewCreated, UserIDs: []int{2}})
+
+	if got := received(alice); len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("user 1 received %+v, want the broadcast", got)
+	}
+	got := received(bob)
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+		t.Fatalf("user 2 received %+v, want both events in order", got)
+	}
+	if got[0].CreatedAt.IsZero() {
+		t.Error("CreatedAt was not set")
+	}
+}
+
+func TestHubDisconnectsSlowConsumer(t *testing.T) {
+	hub := NewMemoryHub(2)
+	defer hub.Close()
+	slow, fast := hub.Subscribe(1), hub.Subscribe(2)
+
+	hub.Publish(Event{Type: EventReviewCreated})
+	hub.Publish(Event{Type: EventReviewCreated})
+	received(fast)
+	if ended(slow) {
+		t.Fatal("a full buffer alone disconnected the subscriber")
+	}
+
+	hub.Publish(Event{Type: EventReviewCreated})
+	if !ended(slow) || !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Fatalf("slow subscriber: ended %v, err %v; want ErrSlowConsumer", ended(slow), slow.Err())
+	}
+	if got := received(fast); len(got) != 1 || got[0].ID != "3" {
+		t.Errorf("fast subscriber received %+v, want event 3", got)
+	}
+
+	// The buffered events can still be read, but nothing more arrives
+	received(slow)
+	hub.Publish(Event{Type: EventReviewCreated})
+	if got := received(slow); len(got) != 0 {
+		t.Errorf("disconnected subscriber received %+v", got)
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	hub := NewMemoryHub(1)
+	defer hub.Close()
+	sub := hub.Subscribe(1)
+	if sub.Err() != nil {
+		t.Fatalf("Err = %v before the subscription ended", sub.Err())
+	}
+
+	sub.Close()
+	sub.Close() // closing twice is harmless
+	if !ended(sub) || sub.Err() != nil {
+		t.Fatalf("ended %v, err %v; want ended without an error", ended(sub), sub.Err())
+	}
+	// A closed subscription is no longer a slow consumer
+	hub.Publish(Event{Type: EventReviewCreated})
+	hub.Publish(Event{Type: EventReviewCreated})
+	if sub.Err() != nil {
+		t.Errorf("Err = %v after publishing", sub.Err())
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewMemoryHub(1)
+	before := hub.Subscribe(1)
+
+	hub.Close()
+	hub.Close()
+	after := hub.Subscribe(1)
+
+	for name, sub := range map[string]Subscription{"before": before, "after": after} {
+		if !ended(sub) || !errors.Is(sub.Err(), ErrHubClosed) {
+			t.Errorf("subscription %s Close: ended %v, err %v; want ErrHubClosed", name, ended(sub), sub.Err())
+		}
+	}
+	hub.Publish(Event{Type: EventReviewCreated})
+	if got := received(after); len(got) != 0 {
+		t.Errorf("subscription after Close received %+v", got)
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditLogEntry) error
+	List(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLogEntry, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *model.AuditLogEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_id, entity_type, entity_id, action, diff)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, entry.ActorID, entry.EntityType, entry.EntityID, entry.Action, []byte(entry.Diff))
+	if err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	entry.ID = id
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLogEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+	if filter.ActorID > 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+
+	query := `SELECT id, actor_id, entity_type, entity_id, action, diff, created_at FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditLogEntry
+	for rows.Next() {
+		entry := &model.AuditLogEntry{}
+		var diff []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&diff,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		entry.Diff = diff
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
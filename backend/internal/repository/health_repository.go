@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	// MigrationVersion returns the highest applied migration, or 0 when none
+	// has been applied yet.
+	MigrationVersion(ctx context.Context) (int, error)
+}
+
+type healthRepository struct {
+	db *sql.DB
+}
+
+func NewHealthRepository(db *sql.DB) HealthRepository {
+	return &healthRepository{db: db}
+}
+
+func (r *healthRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *healthRepository) MigrationVersion(ctx context.Context) (int, error) {
+	var exists bool
+	query := `SELECT COUNT(*) > 0 FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = 'migration_history'`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to check migration history: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM migration_history`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	return version, nil
+}
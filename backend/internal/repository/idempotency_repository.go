@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"protein-web-backend/internal/model"
+)
+
+type IdempotencyRepository interface {
+	// Reserve claims key for a request with fingerprint until ttl has
+	// passed. When the key is taken it returns the stored record and false
+	// instead. A reservation still in progress after lockTimeout (its
+	// request crashed) may be taken over by a request with the same
+	// fingerprint.
+	Reserve(ctx context.Context, userID int, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, bool, error)
+	// Complete stores the response of a reserved request
+	Complete(ctx context.Context, userID int, key string, status int, header http.Header, body []byte) error
+	// Release drops a reservation so that the request can be retried
+	Release(ctx context.Context, userID int, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, userID int, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, idem_key, fingerprint, expires_at)
+		VALUES (?, ?, ?, DATE_ADD(NOW(3), INTERVAL ? MICROSECOND))
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, key, fingerprint, ttl.Microseconds())
+	if err == nil {
+		return nil, true, nil
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlErrDuplicateEntry {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	// Expired keys are free again, and abandoned reservations go to a retry
+	// of the same request
+	query = `
+		UPDATE idempotency_keys
+		SET fingerprint = ?, status_code = NULL, response_header = NULL, response_body = NULL,
+		    created_at = NOW(3), expires_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		WHERE user_id = ? AND idem_key = ?
+		  AND (expires_at < NOW(3)
+		       OR (status_code IS NULL AND fingerprint = ? AND created_at < DATE_SUB(NOW(3), INTERVAL ? MICROSECOND)))
+	`
+	result, err := r.db.ExecContext(ctx, query, fingerprint, ttl.Microseconds(), userID, key, fingerprint, lockTimeout.Microseconds())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to take over idempotency key: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, false, fmt.Errorf("failed to get affected rows: %w", err)
+	} else if n > 0 {
+		return nil, true, nil
+	}
+
+	record := &model.IdempotencyRecord{UserID: userID, Key: key}
+	var status sql.NullInt64
+	var header []byte
+	query = `
+		SELECT fingerprint, status_code, response_header, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = ? AND idem_key = ?
+	`
+	err = r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&record.Fingerprint,
+		&status,
+		&header,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		// Purged in the meantime; the caller may simply try again
+		return nil, false, fmt.Errorf("idempotency key %q disappeared while reserving it", key)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	record.StatusCode = int(status.Int64)
+	if header != nil {
+		if err := json.Unmarshal(header, &record.Header); err != nil {
+			return nil, false, fmt.Errorf("failed to decode stored response header: %w", err)
+		}
+	}
+	return record, false, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, userID int, key string, status int, header http.Header, body []byte) error {
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response header: %w", err)
+	}
+
+	query := `
+		UPDATE idempotency_keys SET status_code = ?, response_header = ?, response_body = ?
+		WHERE user_id = ? AND idem_key = ?
+	`
+	if _, err := r.db.ExecContext(ctx, query, status, encoded, body, userID, key); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, userID int, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE user_id = ? AND idem_key = ? AND status_code IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) PurgeExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW(3)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
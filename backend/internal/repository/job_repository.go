@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"protein-web-backend/internal/model"
+)
+
+// ErrJobLeaseLost means the job is no longer held by the worker, usually
+// because its lease expired and another worker took it over
+var ErrJobLeaseLost = errors.New("job lease lost")
+
+// JobRepository stores the job queue. Times are computed by the database
+// so that workers on different hosts agree on when a job is due.
+type JobRepository interface {
+	// Enqueue inserts job to run after delay. With a UniqueKey that is
+	// already taken nothing is inserted and false is returned.
+	Enqueue(ctx context.Context, job *model.Job, delay time.Duration) (bool, error)
+	// Claim locks the next due job for workerID until lease has passed, or
+	// returns nil when none is due. Jobs whose lease expired are due again.
+	Claim(ctx context.Context, workerID string, lease time.Duration) (*model.Job, error)
+	Complete(ctx context.Context, id int64, workerID string) error
+	// Retry releases a failed job to run again after delay
+	Retry(ctx context.Context, id int64, workerID string, delay time.Duration, lastError string) error
+	// Bury moves a job to the dead-letter state
+	Bury(ctx context.Context, id int64, workerID string, lastError string) error
+	// PurgeFinished deletes completed jobs older than age and returns how
+	// many were removed. Dead jobs are kept.
+	PurgeFinished(ctx context.Context, age time.Duration) (int64, error)
+}
+
+type jobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *model.Job, delay time.Duration) (bool, error) {
+	// ON DUPLICATE KEY keeps the existing row; unlike INSERT IGNORE it does
+	// not hide other errors
+	query := `
+		INSERT INTO jobs (type, payload, max_attempts, run_at, unique_key)
+		VALUES (?, ?, ?, DATE_ADD(NOW(3), INTERVAL ? MICROSECOND), ?)
+		ON DUPLICATE KEY UPDATE id = id
+	`
+	result, err := r.db.ExecContext(ctx, query, job.Type, []byte(job.Payload), job.MaxAttempts, delay.Microseconds(), job.UniqueKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	job.ID = id
+	job.Status = model.JobPending
+	return true, nil
+}
+
+func (r *jobRepository) Claim(ctx context.Context, workerID string, lease time.Duration) (*model.Job, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets concurrent workers pass over rows another worker is
+	// claiming instead of waiting for it
+	query := `
+		SELECT id, type, payload, attempts, max_attempts, last_error, unique_key, created_at
+		FROM jobs
+		WHERE status IN ('pending', 'running') AND run_at <= NOW(3)
+		ORDER BY run_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+	job := &model.Job{}
+	var payload []byte
+	err = tx.QueryRowContext(ctx, query).Scan(
+		&job.ID,
+		&job.Type,
+		&payload,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.UniqueKey,
+		&job.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	job.Payload = payload
+
+	update := `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_by = ?,
+		    run_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, update, workerID, lease.Microseconds(), job.ID); err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit job claim: %w", err)
+	}
+
+	job.Status = model.JobRunning
+	job.Attempts++
+	return job, nil
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id int64, workerID string) error {
+	query := `
+		UPDATE jobs SET status = 'done', locked_by = NULL, finished_at = NOW(3)
+		WHERE id = ? AND status = 'running' AND locked_by = ?
+	`
+	return r.execHeld(ctx, query, id, workerID)
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id int64, workerID string, delay time.Duration, lastError string) error {
+	query := `
+		UPDATE jobs
+		SET status = 'pending', locked_by = NULL, last_error = ?,
+		    run_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		WHERE id = ? AND status = 'running' AND locked_by = ?
+	`
+	return r.execHeld(ctx, query, lastError, delay.Microseconds(), id, workerID)
+}
+
+func (r *jobRepository) Bury(ctx context.Context, id int64, workerID string, lastError string) error {
+	query := `
+		UPDATE jobs SET status = 'dead', locked_by = NULL, last_error = ?, finished_at = NOW(3)
+		WHERE id = ? AND status = 'running' AND locked_by = ?
+	`
+	return r.execHeld(ctx, query, lastError, id, workerID)
+}
+
+func (r *jobRepository) PurgeFinished(ctx context.Context, age time.Duration) (int64, error) {
+	query := `
+		DELETE FROM jobs
+		WHERE status = 'done' AND finished_at < DATE_SUB(NOW(3), INTERVAL ? MICROSECOND)
+	`
+	result, err := r.db.ExecContext(ctx, query, age.Microseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// execHeld runs an update guarded by the worker's lock
+func (r *jobRepository) execHeld(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+)
+
+type ModerationRepository interface {
+	Create(ctx context.Context, result *model.ModerationResult) error
+	List(ctx context.Context, action string, limit, offset int) ([]*model.ModerationResult, error)
+}
+
+type moderationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository(db *sql.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+func (r *moderationRepository) Create(ctx context.Context, result *model.ModerationResult) error {
+	query := `
+		INSERT INTO moderation_results (review_id, user_id, action, original_text, decisions)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	res, err := db.Conn(ctx, r.db).ExecContext(ctx, query, result.ReviewID, result.UserID, result.Action, result.OriginalText, []byte(result.Decisions))
+	if err != nil {
+		return fmt.Errorf("failed to create moderation result: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	result.ID = int(id)
+	return nil
+}
+
+// List returns moderation results newest first. An empty action returns all.
+func (r *moderationRepository) List(ctx context.Context, action string, limit, offset int) ([]*model.ModerationResult, error) {
+	query := `
+		SELECT id, review_id, user_id, action, original_text, decisions, created_at
+		FROM moderation_results
+		WHERE ? = '' OR action = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, action, action, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*model.ModerationResult
+	for rows.Next() {
+		result := &model.ModerationResult{}
+		var decisions []byte
+		err := rows.Scan(
+			&result.ID,
+			&result.ReviewID,
+			&result.UserID,
+			&result.Action,
+			&result.OriginalText,
+			&decisions,
+			&result.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation result: %w", err)
+		}
+		result.Decisions = decisions
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+)
+
+type OutboxRepository interface {
+	// Add stores an event. Call it with the context of the transaction that
+	// makes the change the event describes, so both commit or neither does.
+	Add(ctx context.Context, event *model.OutboxEvent) error
+	// Claim returns up to limit unpublished events that are due and hides
+	// them from other relays until lease has passed. Dead events are never
+	// claimed.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id int64, sink string) error
+	MarkPublished(ctx context.Context, id int64) error
+	// Reschedule makes a partly delivered event due again after delay
+	Reschedule(ctx context.Context, id int64, delay time.Duration, lastError string) error
+	// Bury moves an event that ran out of attempts to the dead-letter state
+	Bury(ctx context.Context, id int64, lastError string) error
+	// PurgePublished deletes published events and consumer records older
+	// than age. Dead events are kept.
+	PurgePublished(ctx context.Context, age time.Duration) (int64, error)
+
+	// Processed reports whether consumer already handled the event key.
+	// It and MarkProcessed take part in a transaction started by InTx.
+	Processed(ctx context.Context, consumer, key string) (bool, error)
+	MarkProcessed(ctx context.Context, consumer, key string) error
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, event *model.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox (event_key, type, aggregate_type, aggregate_id, payload)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, event.Key, event.Type, event.AggregateType, event.AggregateID, []byte(event.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to add outbox event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	event.ID = id
+	return nil
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, event_key, type, aggregate_type, aggregate_id, payload, attempts, created_at
+		FROM outbox
+		WHERE published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= NOW(3)
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*model.OutboxEvent
+	byID := make(map[int64]*model.OutboxEvent)
+	for rows.Next() {
+		event := &model.OutboxEvent{}
+		var payload []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.Key,
+			&event.Type,
+			&event.AggregateType,
+			&event.AggregateID,
+			&payload,
+			&event.Attempts,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = payload
+		event.Attempts++
+		events = append(events, event)
+		byID[event.ID] = event
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(events)), ", ")
+	ids := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	update := `
+		UPDATE outbox
+		SET attempts = attempts + 1, next_attempt_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		WHERE id IN (` + placeholders + `)`
+	if _, err := tx.ExecContext(ctx, update, append([]interface{}{lease.Microseconds()}, ids...)...); err != nil {
+		return nil, fmt.Errorf("failed to lease outbox events: %w", err)
+	}
+
+	deliveries, err := tx.QueryContext(ctx, `SELECT outbox_id, sink FROM outbox_deliveries WHERE outbox_id IN (`+placeholders+`)`, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox deliveries: %w", err)
+	}
+	defer deliveries.Close()
+	for deliveries.Next() {
+		var id int64
+		var sink string
+		if err := deliveries.Scan(&id, &sink); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox delivery: %w", err)
+		}
+		byID[id].DeliveredTo = append(byID[id].DeliveredTo, sink)
+	}
+	if err := deliveries.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, id int64, sink string) error {
+	query := `INSERT INTO outbox_deliveries (outbox_id, sink) VALUES (?, ?) ON DUPLICATE KEY UPDATE sink = sink`
+	if _, err := r.db.ExecContext(ctx, query, id, sink); err != nil {
+		return fmt.Errorf("failed to record outbox delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	query := `UPDATE outbox SET published_at = NOW(3), last_error = NULL WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) Reschedule(ctx context.Context, id int64, delay time.Duration, lastError string) error {
+	query := `
+		UPDATE outbox SET next_attempt_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND), last_error = ?
+		WHERE id = ? AND published_at IS NULL
+	`
+	if _, err := r.db.ExecContext(ctx, query, delay.Microseconds(), lastError, id); err != nil {
+		return fmt.Errorf("failed to reschedule outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) Bury(ctx context.Context, id int64, lastError string) error {
+	query := `UPDATE outbox SET dead_at = NOW(3), last_error = ? WHERE id = ? AND published_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, lastError, id); err != nil {
+		return fmt.Errorf("failed to dead-letter outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) PurgePublished(ctx context.Context, age time.Duration) (int64, error) {
+	var purged int64
+	for _, query := range []string{
+		`DELETE FROM outbox WHERE published_at < DATE_SUB(NOW(3), INTERVAL ? MICROSECOND)`,
+		`DELETE FROM processed_events WHERE processed_at < DATE_SUB(NOW(3), INTERVAL ? MICROSECOND)`,
+	} {
+		result, err := r.db.ExecContext(ctx, query, age.Microseconds())
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge outbox: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return purged, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		purged += n
+	}
+	return purged, nil
+}
+
+func (r *outboxRepository) Processed(ctx context.Context, consumer, key string) (bool, error) {
+	var one int
+	err := db.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT 1 FROM processed_events WHERE consumer = ? AND event_key = ?`, consumer, key).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check processed event: %w", err)
+	}
+	return true, nil
+}
+
+func (r *outboxRepository) MarkProcessed(ctx context.Context, consumer, key string) error {
+	query := `INSERT INTO processed_events (consumer, event_key) VALUES (?, ?) ON DUPLICATE KEY UPDATE consumer = consumer`
+	if _, err := db.Conn(ctx, r.db).ExecContext(ctx, query, consumer, key); err != nil {
+		return fmt.Errorf("failed to mark event processed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	ErrReportNotFound  = errors.New("report not found")
+	ErrAlreadyReported = errors.New("review already reported by this user")
+)
+
+type ReportRepository interface {
+	Create(ctx context.Context, report *model.Report) error
+	GetByID(ctx context.Context, id int) (*model.Report, error)
+	List(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error)
+	CountOpenByReviewID(ctx context.Context, reviewID int) (int, error)
+	Resolve(ctx context.Context, id int, status model.ReportStatus, resolverID int) error
+	// ResolveOpenByReviewID closes the open reports of a review. A
+	// resolverID of 0 records a resolution by the system, such as the
+	// automatic hiding of a review.
+	ResolveOpenByReviewID(ctx context.Context, reviewID int, status model.ReportStatus, resolverID int) error
+	// DismissByReviewID dismisses the open reports of a review together
+	// with those the system actioned when it hid the review
+	DismissByReviewID(ctx context.Context, reviewID int, resolverID int) error
+	ListByReporterID(ctx context.Context, reporterID int) ([]*model.Report, error)
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+const mysqlErrDuplicateEntry = 1062
+
+func (r *reportRepository) Create(ctx context.Context, report *model.Report) error {
+	query := `
+		INSERT INTO reports (review_id, reporter_id, reason, details)
+		VALUES (?, ?, ?, ?)
+	`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, report.ReviewID, report.ReporterID, report.Reason, report.Details)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return ErrAlreadyReported
+		}
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	report.ID = int(id)
+	report.Status = model.ReportStatusOpen
+	return nil
+}
+
+func (r *reportRepository) GetByID(ctx context.Context, id int) (*model.Report, error) {
+	report := &model.Report{}
+	query := `
+		SELECT id, review_id, reporter_id, reason, details, status, resolved_by, resolved_at, created_at
+		FROM reports
+		WHERE id = ?
+	`
+	err := db.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&report.ID,
+		&report.ReviewID,
+		&report.ReporterID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ResolvedBy,
+		&report.ResolvedAt,
+		&report.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	return report, nil
+}
+
+// List returns reports with the given status, oldest first, together with the
+// reported review so moderators can triage without a second request.
+func (r *reportRepository) List(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error) {
+	query := `
+		SELECT p.id, p.review_id, p.reporter_id, p.reason, p.details, p.status, p.resolved_by, p.resolved_at, p.created_at,
+		       r.id, r.user_id, r.protein_per_serving, r.price_per_serving, r.comment, r.hidden_at, r.created_at, r.updated_at
+		FROM reports p
+		JOIN reviews r ON p.review_id = r.id
+		WHERE p.status = ?
+		ORDER BY p.created_at
+		LIMIT ? OFFSET ?
+	`
+	rows, err := db.Conn(ctx, r.db).QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*model.Report
+	for rows.Next() {
+		report := &model.Report{Review: &model.Review{}}
+		err := rows.Scan(
+			&report.ID,
+			&report.ReviewID,
+			&report.ReporterID,
+			&report.Reason,
+			&report.Details,
+			&report.Status,
+			&report.ResolvedBy,
+			&report.ResolvedAt,
+			&report.CreatedAt,
+			&report.Review.ID,
+			&report.Review.UserID,
+			&report.Review.ProteinPerServing,
+			&report.Review.PricePerServing,
+			&report.Review.Comment,
+			&report.Review.HiddenAt,
+			&report.Review.CreatedAt,
+			&report.Review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *reportRepository) CountOpenByReviewID(ctx context.Context, reviewID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM reports WHERE review_id = ? AND status = ?`
+	if err := db.Conn(ctx, r.db).QueryRowContext(ctx, query, reviewID, model.ReportStatusOpen).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+	return count, nil
+}
+
+func (r *reportRepository) Resolve(ctx context.Context, id int, status model.ReportStatus, resolverID int) error {
+	query := `
+		UPDATE reports SET status = ?, resolved_by = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	if _, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, resolverID, id); err != nil {
+		return fmt.Errorf("failed to resolve report: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) ResolveOpenByReviewID(ctx context.Context, reviewID int, status model.ReportStatus, resolverID int) error {
+	query := `
+		UPDATE reports SET status = ?, resolved_by = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE review_id = ? AND status = ?
+	`
+	if _, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, resolver(resolverID), reviewID, model.ReportStatusOpen); err != nil {
+		return fmt.Errorf("failed to resolve reports: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) DismissByReviewID(ctx context.Context, reviewID int, resolverID int) error {
+	query := `
+		UPDATE reports SET status = ?, resolved_by = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE review_id = ? AND (status = ? OR (status = ? AND resolved_by IS NULL))
+	`
+	args := []interface{}{model.ReportStatusDismissed, resolver(resolverID), reviewID, model.ReportStatusOpen, model.ReportStatusActioned}
+	if _, err := db.Conn(ctx, r.db).ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to dismiss reports: %w", err)
+	}
+	return nil
+}
+
+// resolver returns the resolved_by value of resolverID; 0 is the system
+func resolver(resolverID int) interface{} {
+	if resolverID == 0 {
+		return nil
+	}
+	return resolverID
+}
+
+func (r *reportRepository) ListByReporterID(ctx context.Context, reporterID int) ([]*model.Report, error) {
+	query := `
+		SELECT id, review_id, reporter_id, reason, details, status, resolved_by, resolved_at, created_at
+		FROM reports
+		WHERE reporter_id = ?
+		ORDER BY created_at
+	`
+	rows, err := db.Conn(ctx, r.db).QueryContext(ctx, query, reporterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*model.Report
+	for rows.Next() {
+		report := &model.Report{}
+		err := rows.Scan(
+			&report.ID,
+			&report.ReviewID,
+			&report.ReporterID,
+			&report.Reason,
+			&report.Details,
+			&report.Status,
+			&report.ResolvedBy,
+			&report.ResolvedAt,
+			&report.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
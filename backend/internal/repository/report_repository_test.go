@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"protein-web-backend/internal/model"
+)
+
+func TestReportRepositoryResolver(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolve    func(r ReportRepository) error
+		wantStatus model.ReportStatus
+		resolverID interface{}
+	}{
+		{
+			name: "by an admin",
+			resolve: func(r ReportRepository) error {
+				return r.ResolveOpenByReviewID(context.Background(), 1, model.ReportStatusActioned, 9)
+			},
+			wantStatus: model.ReportStatusActioned,
+			resolverID: int64(9),
+		},
+		{
+			name: "by the system",
+			resolve: func(r ReportRepository) error {
+				return r.ResolveOpenByReviewID(context.Background(), 1, model.ReportStatusActioned, 0)
+			},
+			wantStatus: model.ReportStatusActioned,
+			resolverID: nil,
+		},
+		{
+			name:       "dismissed by an admin",
+			resolve:    func(r ReportRepository) error { return r.DismissByReviewID(context.Background(), 1, 9) },
+			wantStatus: model.ReportStatusDismissed,
+			resolverID: int64(9),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &statementLog{affected: 1}
+			if err := tt.resolve(NewReportRepository(sql.OpenDB(log))); err != nil {
+				t.Fatal(err)
+			}
+			if len(log.args) != 1 {
+				t.Fatalf("statements = %q", log.statements)
+			}
+			args := log.args[0]
+			if args[0].Value != string(tt.wantStatus) || args[1].Value != tt.resolverID {
+				t.Errorf("status, resolved_by = %v, %v, want %v, %v", args[0].Value, args[1].Value, tt.wantStatus, tt.resolverID)
+			}
+		})
+	}
+}
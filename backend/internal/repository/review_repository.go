@@ -1,34 +1,70 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
+	"protein-web-backend/internal/db"
 	"protein-web-backend/internal/model"
 )
 
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewImageNotFound = errors.New("review image not found")
+	// ErrReviewModified is returned by versioned writes when the review no
+	// longer has the expected version
+	ErrReviewModified = errors.New("review was modified")
+)
+
+// ReviewRepository read methods never return soft-deleted reviews or images.
+// List methods also exclude hidden reviews unless viewerID is the review's
+// author. A viewerID of 0 means an anonymous viewer.
+//
+// Every write increments the review's version. Writes that take a version
+// only apply while the review still has it and return ErrReviewModified
+// otherwise; a version of 0 applies to any version.
 type ReviewRepository interface {
-	Create(review *model.Review) error
-	CreateImage(image *model.ReviewImage) error
-	GetByID(id int) (*model.Review, error)
-	GetAll(limit, offset int) ([]*model.Review, error)
-	GetByUserID(userID int, limit, offset int) ([]*model.Review, error)
+	Create(ctx context.Context, review *model.Review) error
+	CreateImage(ctx context.Context, image *model.ReviewImage) error
+	GetByID(ctx context.Context, id int) (*model.Review, error)
+	GetAll(ctx context.Context, viewerID, limit, offset int) ([]*model.Review, error)
+	GetByUserID(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error)
+	// Update stores the review's text fields and sets its new version. The
+	// version is required.
+	Update(ctx context.Context, review *model.Review, version int) error
+	SetHidden(ctx context.Context, id int, hidden bool) error
+	SoftDelete(ctx context.Context, id, version int) error
+	Restore(ctx context.Context, id int) error
+	DeleteImage(ctx context.Context, reviewID, imageID, version int) error
+	// ListAllByUserID returns every review of the user, including hidden and
+	// soft-deleted ones, for data export.
+	ListAllByUserID(ctx context.Context, userID int) ([]*model.Review, error)
+}
+
+// queryer is implemented by both *sql.DB and *db.Cluster
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
 
 type reviewRepository struct {
-	db *sql.DB
+	db *db.Cluster
 }
 
-func NewReviewRepository(db *sql.DB) ReviewRepository {
-	return &reviewRepository{db: db}
+// NewReviewRepository sends the feed reads (GetAll, GetByUserID and their
+// image lookups) to the cluster's replica when one is available; all other
+// queries use the primary.
+func NewReviewRepository(cluster *db.Cluster) ReviewRepository {
+	return &reviewRepository{db: cluster}
 }
 
-func (r *reviewRepository) Create(review *model.Review) error {
+func (r *reviewRepository) Create(ctx context.Context, review *model.Review) error {
 	query := `
 		INSERT INTO reviews (user_id, protein_per_serving, price_per_serving, comment)
 		VALUES (?, ?, ?, ?)
 	`
-	result, err := r.db.Exec(query, review.UserID, review.ProteinPerServing, review.PricePerServing, review.Comment)
+	result, err := r.db.ExecContext(ctx, query, review.UserID, review.ProteinPerServing, review.PricePerServing, review.Comment)
 	if err != nil {
 		return fmt.Errorf("failed to create review: %w", err)
 	}
@@ -39,15 +75,16 @@ func (r *reviewRepository) Create(review *model.Review) error {
 	}
 
 	review.ID = int(id)
+	review.Version = 1
 	return nil
 }
 
-func (r *reviewRepository) CreateImage(image *model.ReviewImage) error {
+func (r *reviewRepository) CreateImage(ctx context.Context, image *model.ReviewImage) error {
 	query := `
 		INSERT INTO review_images (review_id, image_url, display_order)
 		VALUES (?, ?, ?)
 	`
-	result, err := r.db.Exec(query, image.ReviewID, image.ImageURL, image.DisplayOrder)
+	result, err := r.db.ExecContext(ctx, query, image.ReviewID, image.ImageURL, image.DisplayOrder)
 	if err != nil {
 		return fmt.Errorf("failed to create review image: %w", err)
 	}
@@ -61,31 +98,33 @@ func (r *reviewRepository) CreateImage(image *model.ReviewImage) error {
 	return nil
 }
 
-func (r *reviewRepository) GetByID(id int) (*model.Review, error) {
+func (r *reviewRepository) GetByID(ctx context.Context, id int) (*model.Review, error) {
 	review := &model.Review{}
 	query := `
-		SELECT id, user_id, protein_per_serving, price_per_serving, comment, created_at, updated_at
+		SELECT id, user_id, protein_per_serving, price_per_serving, comment, hidden_at, version, created_at, updated_at
 		FROM reviews
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&review.ID,
 		&review.UserID,
 		&review.ProteinPerServing,
 		&review.PricePerServing,
 		&review.Comment,
+		&review.HiddenAt,
+		&review.Version,
 		&review.CreatedAt,
 		&review.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("review not found")
+			return nil, ErrReviewNotFound
 		}
 		return nil, fmt.Errorf("failed to get review: %w", err)
 	}
 
 	// Get images
-	images, err := r.getImagesByReviewID(review.ID)
+	images, err := r.getImagesByReviewID(ctx, r.db, review.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -94,16 +133,19 @@ func (r *reviewRepository) GetByID(id int) (*model.Review, error) {
 	return review, nil
 }
 
-func (r *reviewRepository) GetAll(limit, offset int) ([]*model.Review, error) {
+func (r *reviewRepository) GetAll(ctx context.Context, viewerID, limit, offset int) ([]*model.Review, error) {
 	query := `
-		SELECT r.id, r.user_id, r.protein_per_serving, r.price_per_serving, r.comment, r.created_at, r.updated_at,
+		SELECT r.id, r.user_id, r.protein_per_serving, r.price_per_serving, r.comment, r.hidden_at, r.version, r.created_at, r.updated_at,
 		       u.id, u.name, u.email
 		FROM reviews r
 		JOIN users u ON r.user_id = u.id
+		WHERE r.deleted_at IS NULL AND u.deleted_at IS NULL
+		  AND (r.hidden_at IS NULL OR r.user_id = ?)
 		ORDER BY r.created_at DESC
 		LIMIT ? OFFSET ?
 	`
-	rows, err := r.db.Query(query, limit, offset)
+	reader := r.db.Reader(ctx)
+	rows, err := reader.QueryContext(ctx, query, viewerID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviews: %w", err)
 	}
@@ -118,6 +160,8 @@ func (r *reviewRepository) GetAll(limit, offset int) ([]*model.Review, error) {
 			&review.ProteinPerServing,
 			&review.PricePerServing,
 			&review.Comment,
+			&review.HiddenAt,
+			&review.Version,
 			&review.CreatedAt,
 			&review.UpdatedAt,
 			&review.User.ID,
@@ -129,7 +173,7 @@ func (r *reviewRepository) GetAll(limit, offset int) ([]*model.Review, error) {
 		}
 
 		// Get images for each review
-		images, err := r.getImagesByReviewID(review.ID)
+		images, err := r.getImagesByReviewID(ctx, reader, review.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -137,19 +181,23 @@ func (r *reviewRepository) GetAll(limit, offset int) ([]*model.Review, error) {
 
 		reviews = append(reviews, review)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return reviews, nil
 }
 
-func (r *reviewRepository) GetByUserID(userID int, limit, offset int) ([]*model.Review, error) {
+func (r *reviewRepository) GetByUserID(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error) {
 	query := `
-		SELECT id, user_id, protein_per_serving, price_per_serving, comment, created_at, updated_at
+		SELECT id, user_id, protein_per_serving, price_per_serving, comment, hidden_at, version, created_at, updated_at
 		FROM reviews
-		WHERE user_id = ?
+		WHERE user_id = ? AND deleted_at IS NULL AND (hidden_at IS NULL OR user_id = ?)
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
 	`
-	rows, err := r.db.Query(query, userID, limit, offset)
+	reader := r.db.Reader(ctx)
+	rows, err := reader.QueryContext(ctx, query, userID, viewerID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviews by user: %w", err)
 	}
@@ -164,6 +212,8 @@ func (r *reviewRepository) GetByUserID(userID int, limit, offset int) ([]*model.
 			&review.ProteinPerServing,
 			&review.PricePerServing,
 			&review.Comment,
+			&review.HiddenAt,
+			&review.Version,
 			&review.CreatedAt,
 			&review.UpdatedAt,
 		)
@@ -172,7 +222,7 @@ func (r *reviewRepository) GetByUserID(userID int, limit, offset int) ([]*model.
 		}
 
 		// Get images for each review
-		images, err := r.getImagesByReviewID(review.ID)
+		images, err := r.getImagesByReviewID(ctx, reader, review.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -180,18 +230,144 @@ func (r *reviewRepository) GetByUserID(userID int, limit, offset int) ([]*model.
 
 		reviews = append(reviews, review)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return reviews, nil
 }
 
-func (r *reviewRepository) getImagesByReviewID(reviewID int) ([]model.ReviewImage, error) {
+func (r *reviewRepository) Update(ctx context.Context, review *model.Review, version int) error {
+	query := `
+		UPDATE reviews SET protein_per_serving = ?, price_per_serving = ?, comment = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND version = ?
+	`
+	err := r.execAffecting(ctx, query, ErrReviewModified, review.ProteinPerServing, review.PricePerServing, review.Comment, review.ID, version)
+	if err != nil {
+		return err
+	}
+	review.Version = version + 1
+	return nil
+}
+
+func (r *reviewRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	query := `UPDATE reviews SET hidden_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND hidden_at IS NULL`
+	if !hidden {
+		query = `UPDATE reviews SET hidden_at = NULL, version = version + 1 WHERE id = ? AND hidden_at IS NOT NULL`
+	}
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update review visibility: %w", err)
+	}
+	return nil
+}
+
+func (r *reviewRepository) ListAllByUserID(ctx context.Context, userID int) ([]*model.Review, error) {
+	query := `
+		SELECT id, user_id, protein_per_serving, price_per_serving, comment, hidden_at, version, created_at, updated_at, deleted_at
+		FROM reviews
+		WHERE user_id = ?
+		ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews by user: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*model.Review
+	for rows.Next() {
+		review := &model.Review{}
+		err := rows.Scan(
+			&review.ID,
+			&review.UserID,
+			&review.ProteinPerServing,
+			&review.PricePerServing,
+			&review.Comment,
+			&review.HiddenAt,
+			&review.Version,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&review.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, review := range reviews {
+		images, err := r.getImagesByReviewID(ctx, r.db, review.ID)
+		if err != nil {
+			return nil, err
+		}
+		review.Images = images
+	}
+
+	return reviews, nil
+}
+
+// SoftDelete marks a review as deleted without removing the row
+func (r *reviewRepository) SoftDelete(ctx context.Context, id, version int) error {
+	query, args, notMatched := ifVersion(`UPDATE reviews SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = ? AND deleted_at IS NULL`, id, version)
+	return r.execAffecting(ctx, query, notMatched, args...)
+}
+
+// Restore clears the deleted mark of a soft-deleted review
+func (r *reviewRepository) Restore(ctx context.Context, id int) error {
+	return r.execAffecting(ctx, `UPDATE reviews SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL`, ErrReviewNotFound, id)
+}
+
+// DeleteImage soft-deletes one image of a review. The image is part of the
+// review, so the review's version changes with it.
+func (r *reviewRepository) DeleteImage(ctx context.Context, reviewID, imageID, version int) error {
+	return db.InTx(ctx, r.db.Primary(), func(ctx context.Context) error {
+		query, args, notMatched := ifVersion(`UPDATE reviews SET version = version + 1 WHERE id = ? AND deleted_at IS NULL`, reviewID, version)
+		if err := r.execAffecting(ctx, query, notMatched, args...); err != nil {
+			return err
+		}
+		query = `UPDATE review_images SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND review_id = ? AND deleted_at IS NULL`
+		return r.execAffecting(ctx, query, ErrReviewImageNotFound, imageID, reviewID)
+	})
+}
+
+// ifVersion restricts an update of review id to the given version, unless
+// it is 0. It also returns the error to report when no row matches.
+func ifVersion(query string, id, version int) (string, []interface{}, error) {
+	if version == 0 {
+		return query, []interface{}{id}, ErrReviewNotFound
+	}
+	return query + ` AND version = ?`, []interface{}{id, version}, ErrReviewModified
+}
+
+// execAffecting runs an update and returns notFound when no row matched
+func (r *reviewRepository) execAffecting(ctx context.Context, query string, notFound error, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// getImagesByReviewID runs on q, the pool that returned the review, so the
+// images are as fresh as the review itself
+func (r *reviewRepository) getImagesByReviewID(ctx context.Context, q queryer, reviewID int) ([]model.ReviewImage, error) {
 	query := `
 		SELECT id, review_id, image_url, display_order, created_at
 		FROM review_images
-		WHERE review_id = ?
+		WHERE review_id = ? AND deleted_at IS NULL
 		ORDER BY display_order
 	`
-	rows, err := r.db.Query(query, reviewID)
+	rows, err := q.QueryContext(ctx, query, reviewID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get review images: %w", err)
 	}
@@ -212,6 +388,9 @@ func (r *reviewRepository) getImagesByReviewID(reviewID int) ([]model.ReviewImag
 		}
 		images = append(images, image)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return images, nil
 }
\ No newline at end of file
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+)
+
+// reviewsTable stands in for MySQL: it holds one review and one image and
+// applies the UPDATE statements of reviewRepository to them, honouring the
+// version condition and transactions
+type reviewsTable struct {
+	version      int
+	deleted      bool
+	imageDeleted bool
+}
+
+type reviewsConnector struct{ table *reviewsTable }
+
+func (c reviewsConnector) Connect(context.Context) (driver.Conn, error) {
+	return &reviewsConn{table: c.table}, nil
+}
+
+func (c reviewsConnector) Driver() driver.Driver { return nil }
+
+type reviewsConn struct {
+	table   *reviewsTable
+	pending *reviewsTable // the rows as the open transaction sees them
+}
+
+func (c *reviewsConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *reviewsConn) Close() error { return nil }
+
+func (c *reviewsConn) Begin() (driver.Tx, error) {
+	copied := *c.table
+	c.pending = &copied
+	return c, nil
+}
+
+func (c *reviewsConn) Commit() error {
+	*c.table, c.pending = *c.pending, nil
+	return nil
+}
+
+func (c *reviewsConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+func (c *reviewsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	t := c.table
+	if c.pending != nil {
+		t = c.pending
+	}
+	query = strings.Join(strings.Fields(query), " ")
+
+	matched := false
+	switch {
+	case strings.HasPrefix(query, "UPDATE reviews SET"):
+		// The id is the first argument after those of SET
+		id := args[strings.Count(query[:strings.Index(query, "WHERE")], "?")]
+		matched = id.Value == int64(1) && !t.deleted
+		if strings.HasSuffix(query, "AND version = ?") {
+			matched = matched && args[len(args)-1].Value == int64(t.version)
+		}
+		if matched {
+			t.version++
+			t.deleted = t.deleted || strings.Contains(query, "deleted_at = CURRENT_TIMESTAMP")
+		}
+	case strings.HasPrefix(query, "UPDATE review_images SET"):
+		matched = args[0].Value == int64(10) && args[1].Value == int64(1) && !t.imageDeleted
+		t.imageDeleted = t.imageDeleted || matched
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+	if matched {
+		return driver.RowsAffected(1), nil
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func newReviewsRepository(table *reviewsTable) ReviewRepository {
+	conn := sql.OpenDB(reviewsConnector{table: table})
+	conn.SetMaxOpenConns(1)
+	return NewReviewRepository(db.NewCluster(conn, nil, db.ReplicaOptions{}))
+}
+
+func TestReviewRepositoryVersionCheck(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		write       func(r ReviewRepository) error
+		want        error
+		wantVersion int
+		wantDeleted bool
+		wantImage   bool // image deleted
+	}{
+		{
+			name: "update with the current version",
+			write: func(r ReviewRepository) error {
+				return r.Update(ctx, &model.Review{ID: 1}, 3)
+			},
+			wantVersion: 4,
+		},
+		{
+			name: "update with a stale version",
+			write: func(r ReviewRepository) error {
+				return r.Update(ctx, &model.Review{ID: 1}, 2)
+			},
+			want:        ErrReviewModified,
+			wantVersion: 3,
+		},
+		{
+			name:        "delete with the current version",
+			write:       func(r ReviewRepository) error { return r.SoftDelete(ctx, 1, 3) },
+			wantVersion: 4,
+			wantDeleted: true,
+		},
+		{
+			name:        "delete with a stale version",
+			write:       func(r ReviewRepository) error { return r.SoftDelete(ctx, 1, 4) },
+			want:        ErrReviewModified,
+			wantVersion: 3,
+		},
+		{
+			name:        "delete of any version",
+			write:       func(r ReviewRepository) error { return r.SoftDelete(ctx, 1, 0) },
+			wantVersion: 4,
+			wantDeleted: true,
+		},
+		{
+			name:        "delete of a missing review",
+			write:       func(r ReviewRepository) error { return r.SoftDelete(ctx, 2, 0) },
+			want:        ErrReviewNotFound,
+			wantVersion: 3,
+		},
+		{
+			name:        "image delete with the current version",
+			write:       func(r ReviewRepository) error { return r.DeleteImage(ctx, 1, 10, 3) },
+			wantVersion: 4,
+			wantImage:   true,
+		},
+		{
+			name:        "image delete with a stale version",
+			write:       func(r ReviewRepository) error { return r.DeleteImage(ctx, 1, 10, 2) },
+			want:        ErrReviewModified,
+			wantVersion: 3,
+		},
+		{
+			name:        "a missing image leaves the version alone",
+			write:       func(r ReviewRepository) error { return r.DeleteImage(ctx, 1, 11, 3) },
+			want:        ErrReviewImageNotFound,
+			wantVersion: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &reviewsTable{version: 3}
+			err := tt.write(newReviewsRepository(table))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if table.version != tt.wantVersion || table.deleted != tt.wantDeleted || table.imageDeleted != tt.wantImage {
+				t.Errorf("table = %+v, want version %d, deleted %v, image deleted %v", *table, tt.wantVersion, tt.wantDeleted, tt.wantImage)
+			}
+		})
+	}
+}
+
+func TestReviewRepositoryUpdateBumpsVersion(t *testing.T) {
+	review := &model.Review{ID: 1, Version: 3}
+	if err := newReviewsRepository(&reviewsTable{version: 3}).Update(context.Background(), review, 3); err != nil {
+		t.Fatal(err)
+	}
+	if review.Version != 4 {
+		t.Errorf("review.Version = %d, want 4", review.Version)
+	}
+}
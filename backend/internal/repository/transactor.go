@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"protein-web-backend/internal/db"
+)
+
+// Transactor makes several repository calls atomic. Writes of the user,
+// review, report, audit, moderation and outbox repositories made with the
+// context passed to fn belong to the transaction.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.InTx(ctx, t.db, fn)
+}
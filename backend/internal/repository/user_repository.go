@@ -1,15 +1,33 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+
+	"protein-web-backend/internal/db"
 	"protein-web-backend/internal/model"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrEmailTaken is returned by Create when another account, possibly a
+	// soft-deleted one, has the email
+	ErrEmailTaken = errors.New("email is already in use")
 )
 
+// UserRepository read methods never return soft-deleted or erased users
 type UserRepository interface {
-	GetAll() ([]model.User, error)
-	Create(user *model.User) error
-	GetByEmail(email string) (*model.User, error)
-	GetByID(id int) (*model.User, error)
+	GetAll(ctx context.Context) ([]model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByID(ctx context.Context, id int) (*model.User, error)
+	SoftDelete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Erase(ctx context.Context, id int, deleteReviews bool) error
 }
 
 type userRepository struct {
@@ -20,8 +38,8 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{DB: db}
 }
 
-func (r *userRepository) GetAll() ([]model.User, error) {
-	rows, err := r.DB.Query("SELECT id, email, password_hash, name, created_at, updated_at FROM users")
+func (r *userRepository) GetAll(ctx context.Context) ([]model.User, error) {
+	rows, err := db.Conn(ctx, r.DB).QueryContext(ctx, "SELECT id, email, password_hash, name, role, created_at, updated_at FROM users WHERE deleted_at IS NULL AND erased_at IS NULL")
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +50,7 @@ func (r *userRepository) GetAll() ([]model.User, error) {
 		var user model.User
 		var createdAt, updatedAt sql.NullTime
 		
-		if err := rows.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &createdAt, &updatedAt); err != nil {
 			return nil, err
 		}
 		
@@ -46,15 +64,22 @@ func (r *userRepository) GetAll() ([]model.User, error) {
 		
 		users = append(users, user)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return users, nil
 }
 
 // Create creates a new user in the database
-func (r *userRepository) Create(user *model.User) error {
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 	query := `INSERT INTO users (email, password_hash, name) VALUES (?, ?, ?)`
-	result, err := r.DB.Exec(query, user.Email, user.PasswordHash, user.Name)
+	result, err := db.Conn(ctx, r.DB).ExecContext(ctx, query, user.Email, user.PasswordHash, user.Name)
 	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return ErrEmailTaken
+		}
 		return err
 	}
 	
@@ -69,14 +94,14 @@ func (r *userRepository) Create(user *model.User) error {
 }
 
 // GetByEmail retrieves a user by email
-func (r *userRepository) GetByEmail(email string) (*model.User, error) {
-	query := `SELECT id, email, password_hash, name, created_at, updated_at FROM users WHERE email = ?`
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := `SELECT id, email, password_hash, name, role, created_at, updated_at FROM users WHERE email = ? AND deleted_at IS NULL AND erased_at IS NULL`
 	
 	var user model.User
 	var createdAt, updatedAt sql.NullTime
 	
-	err := r.DB.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &createdAt, &updatedAt,
+	err := db.Conn(ctx, r.DB).QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &createdAt, &updatedAt,
 	)
 	
 	if err != nil {
@@ -98,14 +123,14 @@ func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 }
 
 // GetByID retrieves a user by ID
-func (r *userRepository) GetByID(id int) (*model.User, error) {
-	query := `SELECT id, email, password_hash, name, created_at, updated_at FROM users WHERE id = ?`
+func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	query := `SELECT id, email, password_hash, name, role, created_at, updated_at FROM users WHERE id = ? AND deleted_at IS NULL AND erased_at IS NULL`
 	
 	var user model.User
 	var createdAt, updatedAt sql.NullTime
 	
-	err := r.DB.QueryRow(query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &createdAt, &updatedAt,
+	err := db.Conn(ctx, r.DB).QueryRowContext(ctx, query, id).Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &createdAt, &updatedAt,
 	)
 	
 	if err != nil {
@@ -125,3 +150,85 @@ func (r *userRepository) GetByID(id int) (*model.User, error) {
 	
 	return &user, nil
 }
+
+// SoftDelete marks a user as deleted without removing the row
+func (r *userRepository) SoftDelete(ctx context.Context, id int) error {
+	return r.setDeleted(ctx, `UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL AND erased_at IS NULL`, id)
+}
+
+// Restore clears the deleted mark of a soft-deleted user
+func (r *userRepository) Restore(ctx context.Context, id int) error {
+	return r.setDeleted(ctx, `UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL AND erased_at IS NULL`, id)
+}
+
+// Erase irreversibly anonymizes a user. In one transaction it scrubs the
+// account's personal fields, clears the audit diffs of the account and of
+// the user's reviews and images, optionally hard-deletes the reviews
+// (images and reports cascade) and removes the texts kept by the content
+// filter audit.
+func (r *userRepository) Erase(ctx context.Context, id int, deleteReviews bool) error {
+	return db.InTx(ctx, r.DB, func(ctx context.Context) error {
+		conn := db.Conn(ctx, r.DB)
+
+		result, err := conn.ExecContext(ctx, `
+			UPDATE users
+			SET email = CONCAT('erased-', id, '@erased.invalid'), password_hash = '', name = NULL, erased_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND erased_at IS NULL
+		`, id)
+		if err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		} else if n == 0 {
+			return ErrUserNotFound
+		}
+
+		// Review and image diffs hold the comments and image URLs. They are
+		// scrubbed before the reviews may be deleted below.
+		scrubs := []struct {
+			entityType string
+			ids        string
+		}{
+			{model.AuditEntityUser, `?`},
+			{model.AuditEntityReview, `SELECT id FROM reviews WHERE user_id = ?`},
+			{model.AuditEntityReviewImage, `SELECT ri.id FROM review_images ri JOIN reviews rv ON rv.id = ri.review_id WHERE rv.user_id = ?`},
+		}
+		for _, scrub := range scrubs {
+			query := `UPDATE audit_log SET diff = JSON_OBJECT() WHERE entity_type = ? AND entity_id IN (` + scrub.ids + `)`
+			if _, err := conn.ExecContext(ctx, query, scrub.entityType, id); err != nil {
+				return fmt.Errorf("failed to scrub audit log: %w", err)
+			}
+		}
+
+		if deleteReviews {
+			if _, err := conn.ExecContext(ctx, `DELETE FROM reviews WHERE user_id = ?`, id); err != nil {
+				return fmt.Errorf("failed to delete reviews: %w", err)
+			}
+		} else {
+			// The reviews show the author's name, so their ETags must change
+			if _, err := conn.ExecContext(ctx, `UPDATE reviews SET version = version + 1 WHERE user_id = ?`, id); err != nil {
+				return fmt.Errorf("failed to update reviews: %w", err)
+			}
+		}
+		if _, err := conn.ExecContext(ctx, `DELETE FROM moderation_results WHERE user_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete moderation results: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) setDeleted(ctx context.Context, query string, id int) error {
+	result, err := db.Conn(ctx, r.DB).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// statementLog records the statements run on its connections, with the
+// transaction boundaries as "BEGIN", "COMMIT" and "ROLLBACK"
+type statementLog struct {
+	statements []string
+	args       [][]driver.NamedValue
+	// affected is returned for every statement; fail fails statements
+	// starting with it, with err if set
+	affected int64
+	fail     string
+	err      error
+	lastID   int64
+}
+
+func (l *statementLog) Connect(context.Context) (driver.Conn, error) { return l, nil }
+func (l *statementLog) Driver() driver.Driver                        { return nil }
+
+func (l *statementLog) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (l *statementLog) Close() error { return nil }
+
+func (l *statementLog) Begin() (driver.Tx, error) {
+	l.statements = append(l.statements, "BEGIN")
+	return l, nil
+}
+
+func (l *statementLog) Commit() error {
+	l.statements = append(l.statements, "COMMIT")
+	return nil
+}
+
+func (l *statementLog) Rollback() error {
+	l.statements = append(l.statements, "ROLLBACK")
+	return nil
+}
+
+func (l *statementLog) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	l.statements = append(l.statements, query)
+	l.args = append(l.args, args)
+	if l.fail != "" && strings.HasPrefix(query, l.fail) {
+		if l.err != nil {
+			return nil, l.err
+		}
+		return nil, errors.New("statement failed")
+	}
+	return execResult{lastID: l.lastID, affected: l.affected}, nil
+}
+
+type execResult struct{ lastID, affected int64 }
+
+func (r execResult) LastInsertId() (int64, error) { return r.lastID, nil }
+func (r execResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+func TestUserRepositoryCreate(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		name   string
+		err    error
+		want   error
+		wantID int
+	}{
+		{name: "created", wantID: 12},
+		// The email of a soft-deleted account is still unique
+		{name: "duplicate email", err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}, want: ErrEmailTaken},
+		{name: "other error", err: errDown, want: errDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &statementLog{affected: 1, lastID: 12}
+			if tt.err != nil {
+				log.fail, log.err = "INSERT INTO users", tt.err
+			}
+			user := &model.User{Email: "user@example.com"}
+			err := NewUserRepository(sql.OpenDB(log)).Create(context.Background(), user)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if user.ID != tt.wantID {
+				t.Errorf("user.ID = %d, want %d", user.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryErase(t *testing.T) {
+	tests := []struct {
+		name          string
+		deleteReviews bool
+		affected      int64
+		fail          string
+		wantErr       bool
+		want          []string // statement prefixes, in order
+	}{
+		{
+			name:          "delete reviews",
+			deleteReviews: true,
+			affected:      1,
+			want: []string{
+				"BEGIN",
+				"UPDATE users SET email",
+				"UPDATE audit_log SET diff = JSON_OBJECT() WHERE entity_type = ? AND entity_id IN (?)",
+				"UPDATE audit_log SET diff = JSON_OBJECT() WHERE entity_type = ? AND entity_id IN (SELECT id FROM reviews",
+				"UPDATE audit_log SET diff = JSON_OBJECT() WHERE entity_type = ? AND entity_id IN (SELECT ri.id FROM review_images",
+				"DELETE FROM reviews",
+				"DELETE FROM moderation_results",
+				"COMMIT",
+			},
+		},
+		{
+			name:     "anonymize reviews",
+			affected: 1,
+			want: []string{
+				"BEGIN",
+				"UPDATE users SET email",
+				"UPDATE audit_log", "UPDATE audit_log", "UPDATE audit_log",
+				"UPDATE reviews SET version = version + 1",
+				"DELETE FROM moderation_results",
+				"COMMIT",
+			},
+		},
+		{
+			name:    "unknown user",
+			wantErr: true,
+			want:    []string{"BEGIN", "UPDATE users SET email", "ROLLBACK"},
+		},
+		{
+			name:     "a failed scrub rolls back",
+			affected: 1,
+			fail:     "UPDATE audit_log",
+			wantErr:  true,
+			want:     []string{"BEGIN", "UPDATE users SET email", "UPDATE audit_log", "ROLLBACK"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &statementLog{affected: tt.affected, fail: tt.fail}
+			err := NewUserRepository(sql.OpenDB(log)).Erase(context.Background(), 7, tt.deleteReviews)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if len(log.statements) != len(tt.want) {
+				t.Fatalf("statements = %q, want %q", log.statements, tt.want)
+			}
+			for i, prefix := range tt.want {
+				if !strings.HasPrefix(log.statements[i], prefix) {
+					t.Errorf("statement %d = %q, want %q...", i, log.statements[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestUserRepositoryEraseScrubsTheUsersEntities(t *testing.T) {
+	log := &statementLog{affected: 1}
+	if err := NewUserRepository(sql.OpenDB(log)).Erase(context.Background(), 7, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var scrubbed []string
+	for i, query := range log.statements {
+		if !strings.HasPrefix(query, "UPDATE audit_log") {
+			continue
+		}
+		args := log.args[i-1] // BEGIN has no arguments
+		if len(args) != 2 || args[1].Value != int64(7) {
+			t.Errorf("%q args = %v, want the entity type and user 7", query, args)
+			continue
+		}
+		scrubbed = append(scrubbed, args[0].Value.(string))
+	}
+	want := []string{model.AuditEntityUser, model.AuditEntityReview, model.AuditEntityReviewImage}
+	if strings.Join(scrubbed, ",") != strings.Join(want, ",") {
+		t.Errorf("scrubbed entity types = %q, want %q", scrubbed, want)
+	}
+}
+
+// The erasure joins a transaction opened by the caller
+func TestUserRepositoryEraseJoinsTransaction(t *testing.T) {
+	log := &statementLog{affected: 1}
+	conn := sql.OpenDB(log)
+	err := db.InTx(context.Background(), conn, func(ctx context.Context) error {
+		return NewUserRepository(conn).Erase(ctx, 7, true)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(strings.Join(log.statements, "\n"), "BEGIN"); n != 1 {
+		t.Errorf("%d transactions, want 1: %q", n, log.statements)
+	}
+}
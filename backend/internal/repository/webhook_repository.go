@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"protein-web-backend/internal/db"
+	"protein-web-backend/internal/model"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+	GetByID(ctx context.Context, id int) (*model.Webhook, error)
+	List(ctx context.Context, limit, offset int) ([]*model.Webhook, error)
+	// ListSubscribed returns the active webhooks subscribed to eventType
+	ListSubscribed(ctx context.Context, eventType string) ([]*model.Webhook, error)
+	Update(ctx context.Context, webhook *model.Webhook) error
+	Delete(ctx context.Context, id int) error
+
+	// RecordDelivery logs an attempt and tracks consecutive failures. A
+	// webhook whose failures reach disableAfter is deactivated, and true is
+	// returned for the attempt that deactivated it. A disableAfter of 0
+	// leaves the failure count alone, e.g. for test pings.
+	RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery, disableAfter int) (bool, error)
+	ListDeliveries(ctx context.Context, webhookID, limit, offset int) ([]*model.WebhookDelivery, error)
+	PurgeDeliveries(ctx context.Context, age time.Duration) (int64, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookColumns = `id, url, secret, event_types, active, consecutive_failures, disabled_at, created_at, updated_at`
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode event types: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhooks (url, secret, event_types, active)
+		VALUES (?, ?, ?, ?)
+	`
+	result, err := r.db.ExecContext(ctx, query, webhook.URL, webhook.Secret, eventTypes, webhook.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	webhook.ID = int(id)
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	return nil
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id int) (*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepository) List(ctx context.Context, limit, offset int) ([]*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id LIMIT ? OFFSET ?`
+	return r.query(ctx, query, limit, offset)
+}
+
+func (r *webhookRepository) ListSubscribed(ctx context.Context, eventType string) ([]*model.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE active = TRUE AND JSON_CONTAINS(event_types, JSON_QUOTE(?))
+		ORDER BY id
+	`
+	return r.query(ctx, query, eventType)
+}
+
+func (r *webhookRepository) query(ctx context.Context, query string, args ...interface{}) ([]*model.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*model.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	var eventTypes []byte
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&eventTypes,
+		&webhook.Active,
+		&webhook.ConsecutiveFailures,
+		&webhook.DisabledAt,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventTypes, &webhook.EventTypes); err != nil {
+		return nil, fmt.Errorf("failed to decode event types: %w", err)
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode event types: %w", err)
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = ?, secret = ?, event_types = ?, active = ?, consecutive_failures = ?, disabled_at = ?
+		WHERE id = ?
+	`
+	_, err = r.db.ExecContext(ctx, query, webhook.URL, webhook.Secret, eventTypes, webhook.Active, webhook.ConsecutiveFailures, webhook.DisabledAt, webhook.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery, disableAfter int) (bool, error) {
+	disabled := false
+	err := db.InTx(ctx, r.db, func(ctx context.Context) error {
+		conn := db.Conn(ctx, r.db)
+
+		query := `
+			INSERT INTO webhook_deliveries (webhook_id, event_key, event_type, attempt, success, status_code, error, duration_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		result, err := conn.ExecContext(ctx, query, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+			delivery.Success, delivery.StatusCode, delivery.Error, delivery.DurationMs)
+		if err != nil {
+			return fmt.Errorf("failed to record webhook delivery: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		delivery.ID = id
+		delivery.CreatedAt = time.Now()
+
+		if disableAfter <= 0 {
+			return nil
+		}
+		if delivery.Success {
+			_, err := conn.ExecContext(ctx, `UPDATE webhooks SET consecutive_failures = 0 WHERE id = ?`, delivery.WebhookID)
+			if err != nil {
+				return fmt.Errorf("failed to reset webhook failures: %w", err)
+			}
+			return nil
+		}
+
+		if _, err := conn.ExecContext(ctx, `UPDATE webhooks SET consecutive_failures = consecutive_failures + 1 WHERE id = ?`, delivery.WebhookID); err != nil {
+			return fmt.Errorf("failed to count webhook failure: %w", err)
+		}
+		query = `
+			UPDATE webhooks SET active = FALSE, disabled_at = NOW(3)
+			WHERE id = ? AND active = TRUE AND consecutive_failures >= ?
+		`
+		result, err = conn.ExecContext(ctx, query, delivery.WebhookID, disableAfter)
+		if err != nil {
+			return fmt.Errorf("failed to disable webhook: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		disabled = n > 0
+		return nil
+	})
+	return disabled, err
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID, limit, offset int) ([]*model.WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, event_key, event_type, attempt, success, status_code, error, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery := &model.WebhookDelivery{}
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempt,
+			&delivery.Success,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.DurationMs,
+			&delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *webhookRepository) PurgeDeliveries(ctx context.Context, age time.Duration) (int64, error) {
+	query := `DELETE FROM webhook_deliveries WHERE created_at < DATE_SUB(NOW(3), INTERVAL ? MICROSECOND)`
+	result, err := r.db.ExecContext(ctx, query, age.Microseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,89 @@
+// Package respond writes JSON bodies and RFC 9457 problem details. Handlers,
+// middleware and the router all render errors through it so clients see a
+// single error format.
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/service"
+	"protein-web-backend/internal/types"
+)
+
+const problemContentType = "application/problem+json"
+
+// JSON writes v with the given status
+func JSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Problem writes a problem details body
+func Problem(w http.ResponseWriter, r *http.Request, p types.Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Status writes a problem with the given status and detail message
+func Status(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	Problem(w, r, types.Problem{Status: status, Detail: detail})
+}
+
+// Error maps a service error to its HTTP status. Unrecognised errors are
+// logged and reported as 500 without exposing their message.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		validation   *service.ValidationError
+		notFound     *service.NotFoundError
+		conflict     *service.ConflictError
+		precondition *service.PreconditionFailedError
+		forbidden    *service.ForbiddenError
+		unauthorized *service.UnauthorizedError
+		rejected     *service.ContentRejectedError
+	)
+
+	switch {
+	case errors.As(err, &validation):
+		Problem(w, r, types.Problem{Status: http.StatusBadRequest, Detail: validation.Message, Errors: validation.Fields})
+	case errors.As(err, &notFound):
+		Status(w, r, http.StatusNotFound, notFound.Error())
+	case errors.As(err, &conflict):
+		Status(w, r, http.StatusConflict, conflict.Error())
+	case errors.As(err, &precondition):
+		Status(w, r, http.StatusPreconditionFailed, precondition.Error())
+	case errors.As(err, &forbidden):
+		Status(w, r, http.StatusForbidden, forbidden.Error())
+	case errors.As(err, &unauthorized):
+		Status(w, r, http.StatusUnauthorized, unauthorized.Error())
+	case errors.As(err, &rejected):
+		fields := make([]types.FieldError, 0, len(rejected.Reasons))
+		for _, reason := range rejected.Reasons {
+			fields = append(fields, types.FieldError{Field: rejected.Field, Message: reason})
+		}
+		Problem(w, r, types.Problem{Status: http.StatusUnprocessableEntity, Detail: "content rejected by moderation", Errors: fields})
+	case errors.Is(err, context.DeadlineExceeded):
+		Status(w, r, http.StatusServiceUnavailable, "request timed out")
+	case errors.Is(err, context.Canceled):
+		// The client went away; nobody reads this response
+		Status(w, r, http.StatusServiceUnavailable, "request cancelled")
+	default:
+		logging.FromContext(r.Context()).Error("unhandled error", "error", err)
+		Status(w, r, http.StatusInternalServerError, "Internal server error")
+	}
+}
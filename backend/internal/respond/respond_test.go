@@ -0,0 +1,30 @@
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/service"
+	"protein-web-backend/internal/types"
+)
+
+func TestErrorContentRejected(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/reviews", nil)
+	w := httptest.NewRecorder()
+
+	Error(w, r, &service.ContentRejectedError{Field: "name", Reasons: []string{"banned word", "link"}})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	var problem types.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	want := []types.FieldError{{Field: "name", Message: "banned word"}, {Field: "name", Message: "link"}}
+	if !reflect.DeepEqual(problem.Errors, want) {
+		t.Errorf("errors = %+v, want %+v", problem.Errors, want)
+	}
+}
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"protein-web-backend/internal/respond"
+)
+
+// Middleware wraps a handler, e.g. to require authentication
+type Middleware func(http.Handler) http.Handler
+
+// methods are the request methods probed when building the Allow header
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// Router registers method-aware routes on an http.ServeMux. Routes use the
+// ServeMux pattern syntax, so path parameters such as /reviews/{id} are read
+// with r.PathValue("id"). Groups share the mux and add a path prefix and
+// middleware of their own.
+type Router struct {
+	mux        *http.ServeMux
+	prefix     string
+	middleware []Middleware
+	routes     *[]Route
+}
+
+// Route is a registered method and full path pattern
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+func New() *Router {
+	return &Router{mux: http.NewServeMux(), routes: &[]Route{}}
+}
+
+// Group returns a router whose routes are mounted under prefix and wrapped
+// in the parent's middleware followed by mw.
+func (rt *Router) Group(prefix string, mw ...Middleware) *Router {
+	middleware := make([]Middleware, 0, len(rt.middleware)+len(mw))
+	middleware = append(middleware, rt.middleware...)
+	middleware = append(middleware, mw...)
+
+	return &Router{
+		mux:        rt.mux,
+		prefix:     rt.prefix + prefix,
+		middleware: middleware,
+		routes:     rt.routes,
+	}
+}
+
+// Use appends middleware to routes registered on this router afterwards
+func (rt *Router) Use(mw ...Middleware) {
+	rt.middleware = append(rt.middleware, mw...)
+}
+
+func (rt *Router) Handle(method, pattern string, h http.Handler) {
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		h = rt.middleware[i](h)
+	}
+	rt.mux.Handle(method+" "+rt.path(pattern), h)
+	*rt.routes = append(*rt.routes, Route{Method: method, Pattern: rt.path(pattern)})
+}
+
+// Routes lists every route registered on the router or any of its groups
+func (rt *Router) Routes() []Route {
+	return append([]Route(nil), *rt.routes...)
+}
+
+func (rt *Router) Get(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodGet, pattern, h)
+}
+
+func (rt *Router) Post(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodPost, pattern, h)
+}
+
+func (rt *Router) Put(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodPut, pattern, h)
+}
+
+func (rt *Router) Patch(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodPatch, pattern, h)
+}
+
+func (rt *Router) Delete(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodDelete, pattern, h)
+}
+
+// ServeHTTP dispatches to the matching route. Unknown paths get a problem 404;
+// known paths requested with the wrong method get a problem 405 with an Allow
+// header listing the methods that are registered.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := rt.mux.Handler(r); pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	if allowed := rt.allowedMethods(r); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		respond.Status(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	respond.Status(w, r, http.StatusNotFound, "Not found")
+}
+
+func (rt *Router) allowedMethods(r *http.Request) []string {
+	var allowed []string
+	for _, method := range methods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := rt.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+func (rt *Router) path(pattern string) string {
+	p := path.Join(rt.prefix, pattern)
+	if strings.HasSuffix(pattern, "/") && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return p
+}
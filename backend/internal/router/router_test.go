@@ -0,0 +1,84 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/types"
+)
+
+func TestRouterServeHTTP(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.PathValue("id")))
+	}
+	rt := New()
+	api := rt.Group("/api/v1")
+	api.Get("/reviews/{id}", ok)
+	api.Delete("/reviews/{id}", ok)
+	api.Post("/reviews", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		allow  string
+		body   string // of a successful response
+	}{
+		{name: "route", method: http.MethodGet, path: "/api/v1/reviews/3", status: http.StatusOK, body: "GET 3"},
+		{name: "second method of a path", method: http.MethodDelete, path: "/api/v1/reviews/3", status: http.StatusOK, body: "DELETE 3"},
+		{name: "unregistered method", method: http.MethodPut, path: "/api/v1/reviews/3", status: http.StatusMethodNotAllowed, allow: "GET, HEAD, DELETE"},
+		{name: "single method path", method: http.MethodGet, path: "/api/v1/reviews", status: http.StatusMethodNotAllowed, allow: "POST"},
+		{name: "unknown path", method: http.MethodGet, path: "/api/v1/users/3", status: http.StatusNotFound},
+		{name: "path outside the group", method: http.MethodGet, path: "/reviews/3", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow = %q, want %q", got, tt.allow)
+			}
+			if tt.status == http.StatusOK {
+				if w.Body.String() != tt.body {
+					t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+				}
+				return
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", ct)
+			}
+			var problem types.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("body %s: %v", w.Body.String(), err)
+			}
+			if problem.Status != tt.status || problem.Title != http.StatusText(tt.status) {
+				t.Errorf("problem = %+v, want status %d", problem, tt.status)
+			}
+		})
+	}
+}
+
+func TestRouterRoutes(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+	rt := New()
+	rt.Get("/healthz", noop)
+	rt.Group("/api/v1").Group("/admin").Patch("/reports/{id}/", noop)
+
+	want := []Route{
+		{Method: http.MethodGet, Pattern: "/healthz"},
+		{Method: http.MethodPatch, Pattern: "/api/v1/admin/reports/{id}/"},
+	}
+	if got := rt.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes = %+v, want %+v", got, want)
+	}
+}
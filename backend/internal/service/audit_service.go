@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// FieldChange is one entry of an audit diff
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type AuditService interface {
+	// Record stores who changed what. before and after are the entity as
+	// it was and as it is now; either may be nil for creations and deletions.
+	// An actorID of 0 records a system change.
+	Record(ctx context.Context, actorID int, entityType string, entityID int, action string, before, after interface{}) error
+	List(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLogEntry, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) Record(ctx context.Context, actorID int, entityType string, entityID int, action string, before, after interface{}) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to compute audit diff: %w", err)
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit diff: %w", err)
+	}
+
+	entry := &model.AuditLogEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Diff:       encoded,
+	}
+	if actorID > 0 {
+		entry.ActorID = &actorID
+	}
+
+	return s.repo.Create(ctx, entry)
+}
+
+func (s *auditService) List(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLogEntry, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.List(ctx, filter, limit, offset)
+}
+
+// diff compares the JSON representations of before and after field by field,
+// so fields hidden from JSON (such as password hashes) never reach the log.
+func diff(before, after interface{}) (map[string]FieldChange, error) {
+	from, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for key, value := range from {
+		if !reflect.DeepEqual(value, to[key]) {
+			changes[key] = FieldChange{From: value, To: to[key]}
+		}
+	}
+	for key, value := range to {
+		if _, seen := from[key]; !seen {
+			changes[key] = FieldChange{To: value}
+		}
+	}
+	return changes, nil
+}
+
+// flag builds one side of the diff for state toggles such as deleted or hidden
+func flag(name string, value bool) map[string]interface{} {
+	return map[string]interface{}{name: value}
+}
+
+func toFields(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil {
+		return fields, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
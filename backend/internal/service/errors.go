@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"protein-web-backend/internal/repository"
+	"protein-web-backend/internal/types"
+)
+
+// FieldError describes why one input field was rejected
+type FieldError = types.FieldError
+
+// ValidationError reports invalid input, optionally per field
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+" "+f.Message)
+	}
+	return e.Message + ": " + strings.Join(parts, ", ")
+}
+
+// NewFieldError returns a ValidationError for a single field
+func NewFieldError(field, message string) *ValidationError {
+	return &ValidationError{
+		Message: "validation failed",
+		Fields:  []FieldError{{Field: field, Message: message}},
+	}
+}
+
+// NotFoundError reports that a resource does not exist or is not visible to
+// the caller.
+type NotFoundError struct {
+	Resource string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Resource + " not found"
+}
+
+// ConflictError reports that the request conflicts with existing state
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// PreconditionFailedError reports that the resource no longer matches the
+// version the caller based its change on
+type PreconditionFailedError struct {
+	Message string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError reports that the caller may not perform the action
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// UnauthorizedError reports failed authentication
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// translate converts repository sentinel errors into domain errors. Other
+// errors are returned unchanged and treated as internal failures.
+func translate(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrReviewNotFound):
+		return &NotFoundError{Resource: "review"}
+	case errors.Is(err, repository.ErrReviewImageNotFound):
+		return &NotFoundError{Resource: "review image"}
+	case errors.Is(err, repository.ErrReportNotFound):
+		return &NotFoundError{Resource: "report"}
+	case errors.Is(err, repository.ErrUserNotFound):
+		return &NotFoundError{Resource: "user"}
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		return &NotFoundError{Resource: "webhook"}
+	case errors.Is(err, repository.ErrReviewModified):
+		return &PreconditionFailedError{Message: "review has been modified since it was read"}
+	case errors.Is(err, repository.ErrAlreadyReported):
+		return &ConflictError{Message: err.Error()}
+	case errors.Is(err, repository.ErrEmailTaken):
+		return errEmailTaken
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// recordEvent adds a domain event to the outbox. Call it inside the
+// transaction of the change it describes; the relay publishes it after the
+// commit.
+func recordEvent(ctx context.Context, outbox repository.OutboxRepository, eventType, aggregateType string, aggregateID int, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	key := make([]byte, 16)
+	rand.Read(key)
+	return outbox.Add(ctx, &model.OutboxEvent{
+		Key:           hex.EncodeToString(key),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+	})
+}
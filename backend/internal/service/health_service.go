@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+type HealthService interface {
+	// Readiness checks that the database answers within the timeout and has
+	// the schema version this build expects.
+	Readiness(ctx context.Context) *model.Readiness
+}
+
+type healthService struct {
+	repo            repository.HealthRepository
+	expectedVersion int
+	timeout         time.Duration
+}
+
+func NewHealthService(repo repository.HealthRepository, expectedVersion int, timeout time.Duration) HealthService {
+	return &healthService{repo: repo, expectedVersion: expectedVersion, timeout: timeout}
+}
+
+func (s *healthService) Readiness(ctx context.Context) *model.Readiness {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	result := &model.Readiness{Status: model.HealthOK, Checks: map[string]string{}}
+	fail := func(check, reason string) {
+		result.Status = model.HealthUnavailable
+		result.Checks[check] = reason
+	}
+
+	// The probe is unauthenticated, so error details go to the log only
+	if err := s.repo.Ping(ctx); err != nil {
+		logging.FromContext(ctx).Warn("readiness: database ping failed", "error", err)
+		fail("database", "unreachable")
+		fail("migrations", "skipped")
+		return result
+	}
+	result.Checks["database"] = model.HealthOK
+
+	version, err := s.repo.MigrationVersion(ctx)
+	switch {
+	case err != nil:
+		logging.FromContext(ctx).Warn("readiness: migration version check failed", "error", err)
+		fail("migrations", "version check failed")
+	case version != s.expectedVersion:
+		fail("migrations", fmt.Sprintf("schema is at version %d, expected %d", version, s.expectedVersion))
+	default:
+		result.Checks["migrations"] = model.HealthOK
+	}
+	return result
+}
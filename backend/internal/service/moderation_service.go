@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/moderation"
+	"protein-web-backend/internal/repository"
+)
+
+// ContentRejectedError is returned when the content filters reject a submission
+type ContentRejectedError struct {
+	// Field is the JSON name of the rejected request field
+	Field   string
+	Reasons []string
+}
+
+func (e *ContentRejectedError) Error() string {
+	return "content rejected: " + strings.Join(e.Reasons, ", ")
+}
+
+type ModerationService interface {
+	// Screen runs the filter chain over text submitted by userID in the
+	// request field named field. Rejections are recorded and returned as
+	// *ContentRejectedError.
+	Screen(ctx context.Context, userID int, field, text string) (*moderation.Outcome, error)
+	// Record stores the outcome for a submission that was accepted as reviewID.
+	// Outcomes where no filter fired are not recorded.
+	Record(ctx context.Context, userID, reviewID int, text string, outcome *moderation.Outcome) error
+	ListResults(ctx context.Context, action string, limit, offset int) ([]*model.ModerationResult, error)
+}
+
+type moderationService struct {
+	repo  repository.ModerationRepository
+	chain *moderation.Chain
+}
+
+func NewModerationService(repo repository.ModerationRepository, chain *moderation.Chain) ModerationService {
+	return &moderationService{
+		repo:  repo,
+		chain: chain,
+	}
+}
+
+func (s *moderationService) Screen(ctx context.Context, userID int, field, text string) (*moderation.Outcome, error) {
+	outcome := s.chain.Run(text)
+	if outcome.Action != moderation.ActionReject {
+		return outcome, nil
+	}
+
+	if err := s.save(ctx, userID, nil, text, outcome); err != nil {
+		return nil, err
+	}
+	return nil, &ContentRejectedError{Field: field, Reasons: outcome.Reasons()}
+}
+
+func (s *moderationService) Record(ctx context.Context, userID, reviewID int, text string, outcome *moderation.Outcome) error {
+	if outcome.Action == moderation.ActionAllow {
+		return nil
+	}
+	return s.save(ctx, userID, &reviewID, text, outcome)
+}
+
+func (s *moderationService) ListResults(ctx context.Context, action string, limit, offset int) ([]*model.ModerationResult, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.List(ctx, action, limit, offset)
+}
+
+func (s *moderationService) save(ctx context.Context, userID int, reviewID *int, text string, outcome *moderation.Outcome) error {
+	decisions, err := json.Marshal(outcome.Decisions)
+	if err != nil {
+		return fmt.Errorf("failed to encode moderation decisions: %w", err)
+	}
+
+	return s.repo.Create(ctx, &model.ModerationResult{
+		ReviewID:     reviewID,
+		UserID:       userID,
+		Action:       outcome.Action.String(),
+		OriginalText: text,
+		Decisions:    decisions,
+	})
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// ErasurePolicy decides what happens to a user's reviews when the account
+// is erased.
+type ErasurePolicy string
+
+const (
+	// ErasureDeleteReviews hard-deletes the reviews together with their images
+	ErasureDeleteReviews ErasurePolicy = "delete"
+	// ErasureAnonymizeReviews keeps the reviews, attributed to the anonymized account
+	ErasureAnonymizeReviews ErasurePolicy = "anonymize"
+)
+
+// uploadURLPrefix is the URL path under which uploaded images are served from
+// the upload directory. Images hosted elsewhere are exported as URLs only.
+const uploadURLPrefix = "/uploads/"
+
+type PrivacyService interface {
+	// ExportUserData collects the user's data; WriteArchive turns it into a
+	// zip file. They are separate so that lookup errors surface before any
+	// bytes are sent.
+	ExportUserData(ctx context.Context, userID int) (*model.UserDataExport, error)
+	WriteArchive(ctx context.Context, export *model.UserDataExport, w io.Writer) error
+	// EraseOwnAccount re-checks the password before erasing
+	EraseOwnAccount(ctx context.Context, userID int, password string) error
+	EraseUser(ctx context.Context, actorID, userID int) error
+}
+
+type privacyService struct {
+	userRepo   repository.UserRepository
+	reviewRepo repository.ReviewRepository
+	reportRepo repository.ReportRepository
+	audit      AuditService
+	tx         repository.Transactor
+	policy     ErasurePolicy
+	uploadDir  string
+	cache      *ReviewCache
+}
+
+func NewPrivacyService(userRepo repository.UserRepository, reviewRepo repository.ReviewRepository, reportRepo repository.ReportRepository, audit AuditService, tx repository.Transactor, policy ErasurePolicy, uploadDir string, cache *ReviewCache) PrivacyService {
+	return &privacyService{
+		userRepo:   userRepo,
+		reviewRepo: reviewRepo,
+		reportRepo: reportRepo,
+		audit:      audit,
+		tx:         tx,
+		policy:     policy,
+		uploadDir:  uploadDir,
+		cache:      cache,
+	}
+}
+
+func (s *privacyService) ExportUserData(ctx context.Context, userID int) (*model.UserDataExport, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user data: %w", err)
+	}
+	if user == nil {
+		return nil, &NotFoundError{Resource: "user"}
+	}
+
+	reviews, err := s.reviewRepo.ListAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := s.reportRepo.ListByReporterID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserDataExport{
+		ExportedAt: time.Now(),
+		Account:    user,
+		Reviews:    reviews,
+		Reports:    reports,
+	}, nil
+}
+
+func (s *privacyService) WriteArchive(ctx context.Context, export *model.UserDataExport, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"account.json", export.Account},
+		{"reviews.json", export.Reviews},
+		{"reports.json", export.Reports},
+	}
+	for _, f := range files {
+		if err := writeJSONFile(archive, f.name, f.data); err != nil {
+			return err
+		}
+	}
+
+	for _, review := range export.Reviews {
+		for _, image := range review.Images {
+			// Stop copying files once the client has gone away
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.addUploadedImage(archive, review.ID, image); err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *privacyService) EraseOwnAccount(ctx context.Context, userID int, password string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user data: %w", err)
+	}
+	if user == nil {
+		return &NotFoundError{Resource: "user"}
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return &ForbiddenError{Message: "invalid password"}
+	}
+
+	return s.EraseUser(ctx, userID, userID)
+}
+
+func (s *privacyService) EraseUser(ctx context.Context, actorID, userID int) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Erase(ctx, userID, s.policy == ErasureDeleteReviews); err != nil {
+			return translate(err)
+		}
+		return s.audit.Record(ctx, actorID, model.AuditEntityUser, userID, model.AuditActionErase, nil, map[string]interface{}{"reviews": s.policy})
+	})
+	if err != nil {
+		return err
+	}
+	// Reviews were deleted or now show an anonymized author
+	s.cache.Invalidate(ctx)
+	return nil
+}
+
+// addUploadedImage copies an image served from the upload directory into the
+// archive. Missing files and external URLs are skipped; their URLs are
+// already listed in reviews.json.
+func (s *privacyService) addUploadedImage(archive *zip.Writer, reviewID int, image model.ReviewImage) error {
+	u, err := url.Parse(image.ImageURL)
+	if err != nil || !strings.HasPrefix(u.Path, uploadURLPrefix) {
+		return nil
+	}
+
+	rel := path.Clean("/" + strings.TrimPrefix(u.Path, uploadURLPrefix))
+	file, err := os.Open(filepath.Join(s.uploadDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	name := fmt.Sprintf("images/%d/%d_%s", reviewID, image.DisplayOrder, path.Base(rel))
+	dst, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, file)
+	return err
+}
+
+func writeJSONFile(archive *zip.Writer, name string, v interface{}) error {
+	dst, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(dst)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
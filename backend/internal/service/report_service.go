@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+type ReportService interface {
+	ReportReview(ctx context.Context, reporterID, reviewID int, req *model.CreateReportRequest) (*model.Report, error)
+	ListReports(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error)
+	DismissReport(ctx context.Context, adminID, reportID int) error
+	HideReview(ctx context.Context, adminID, reviewID int) error
+	RestoreReview(ctx context.Context, adminID, reviewID int) error
+}
+
+type reportService struct {
+	reportRepo    repository.ReportRepository
+	reviewRepo    repository.ReviewRepository
+	audit         AuditService
+	tx            repository.Transactor
+	hideThreshold int
+	cache         *ReviewCache
+}
+
+// NewReportService creates a ReportService. A review is hidden automatically
+// once it has hideThreshold open reports, which closes them as actioned by
+// the system; zero disables automatic hiding.
+func NewReportService(reportRepo repository.ReportRepository, reviewRepo repository.ReviewRepository, audit AuditService, tx repository.Transactor, hideThreshold int, cache *ReviewCache) ReportService {
+	return &reportService{
+		reportRepo:    reportRepo,
+		reviewRepo:    reviewRepo,
+		audit:         audit,
+		tx:            tx,
+		hideThreshold: hideThreshold,
+		cache:         cache,
+	}
+}
+
+func (s *reportService) ReportReview(ctx context.Context, reporterID, reviewID int, req *model.CreateReportRequest) (*model.Report, error) {
+	if !req.Reason.Valid() {
+		return nil, NewFieldError("reason", "must be one of spam, harassment, hate_speech, misinformation, inappropriate, other")
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, translate(err)
+	}
+	if review.UserID == reporterID {
+		return nil, &ForbiddenError{Message: "cannot report your own review"}
+	}
+
+	report := &model.Report{
+		ReviewID:   reviewID,
+		ReporterID: reporterID,
+		Reason:     req.Reason,
+	}
+	if details := strings.TrimSpace(req.Details); details != "" {
+		report.Details = &details
+	}
+
+	hidden := false
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reportRepo.Create(ctx, report); err != nil {
+			return translate(err)
+		}
+		if s.hideThreshold <= 0 || review.HiddenAt != nil {
+			return nil
+		}
+
+		count, err := s.reportRepo.CountOpenByReviewID(ctx, reviewID)
+		if err != nil || count < s.hideThreshold {
+			return err
+		}
+		if err := s.reviewRepo.SetHidden(ctx, reviewID, true); err != nil {
+			return fmt.Errorf("failed to auto-hide review: %w", err)
+		}
+		if err := s.audit.Record(ctx, 0, model.AuditEntityReview, reviewID, model.AuditActionHide, flag("hidden", false), flag("hidden", true)); err != nil {
+			return err
+		}
+		// The reports have been acted on; keep them out of the moderation queue
+		hidden = true
+		return s.reportRepo.ResolveOpenByReviewID(ctx, reviewID, model.ReportStatusActioned, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		s.cache.Invalidate(ctx)
+		report.Status = model.ReportStatusActioned
+	}
+
+	return report, nil
+}
+
+func (s *reportService) ListReports(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error) {
+	if status == "" {
+		status = model.ReportStatusOpen
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.reportRepo.List(ctx, status, limit, offset)
+}
+
+func (s *reportService) DismissReport(ctx context.Context, adminID, reportID int) error {
+	if _, err := s.reportRepo.GetByID(ctx, reportID); err != nil {
+		return translate(err)
+	}
+	return s.reportRepo.Resolve(ctx, reportID, model.ReportStatusDismissed, adminID)
+}
+
+// HideReview hides a review and closes its open reports as actioned
+func (s *reportService) HideReview(ctx context.Context, adminID, reviewID int) error {
+	if _, err := s.reviewRepo.GetByID(ctx, reviewID); err != nil {
+		return translate(err)
+	}
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.SetHidden(ctx, reviewID, true); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, adminID, model.AuditEntityReview, reviewID, model.AuditActionHide, flag("hidden", false), flag("hidden", true)); err != nil {
+			return err
+		}
+		return s.reportRepo.ResolveOpenByReviewID(ctx, reviewID, model.ReportStatusActioned, adminID)
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
+}
+
+// RestoreReview makes a hidden review visible again and dismisses the
+// reports that caused it to be hidden: the open ones and those closed by
+// the automatic hiding.
+func (s *reportService) RestoreReview(ctx context.Context, adminID, reviewID int) error {
+	if _, err := s.reviewRepo.GetByID(ctx, reviewID); err != nil {
+		return translate(err)
+	}
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.SetHidden(ctx, reviewID, false); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, adminID, model.AuditEntityReview, reviewID, model.AuditActionUnhide, flag("hidden", true), flag("hidden", false)); err != nil {
+			return err
+		}
+		return s.reportRepo.DismissByReviewID(ctx, reviewID, adminID)
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// txReportRepository has openReports open reports on every review before
+// the one being created
+type txReportRepository struct {
+	repository.ReportRepository
+	tx          *fakeTransactor
+	openReports int
+}
+
+func (r *txReportRepository) Create(ctx context.Context, report *model.Report) error {
+	r.tx.write(ctx, "create report")
+	report.ID = 1
+	report.Status = model.ReportStatusOpen
+	return nil
+}
+
+func (r *txReportRepository) CountOpenByReviewID(ctx context.Context, reviewID int) (int, error) {
+	return r.openReports + 1, nil
+}
+
+func (r *txReportRepository) ResolveOpenByReviewID(ctx context.Context, reviewID int, status model.ReportStatus, resolverID int) error {
+	r.tx.write(ctx, fmt.Sprintf("resolve reports of %d as %s by %d", reviewID, status, resolverID))
+	return nil
+}
+
+func (r *txReportRepository) DismissByReviewID(ctx context.Context, reviewID int, resolverID int) error {
+	r.tx.write(ctx, fmt.Sprintf("dismiss reports of %d by %d", reviewID, resolverID))
+	return nil
+}
+
+type hidingReviewRepository struct {
+	repository.ReviewRepository
+	tx     *fakeTransactor
+	review model.Review
+}
+
+func (r *hidingReviewRepository) GetByID(ctx context.Context, id int) (*model.Review, error) {
+	review := r.review
+	return &review, nil
+}
+
+func (r *hidingReviewRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	r.tx.write(ctx, fmt.Sprintf("set review %d hidden %v", id, hidden))
+	return nil
+}
+
+func TestReportReviewAutoHide(t *testing.T) {
+	errAudit := errors.New("audit log unavailable")
+	hiddenAt := time.Now()
+
+	tests := []struct {
+		name        string
+		openReports int
+		hiddenAt    *time.Time
+		auditErr    error
+		wantErr     error
+		wantStatus  model.ReportStatus
+		committed   []string
+	}{
+		{
+			name:        "below the threshold",
+			openReports: 1,
+			wantStatus:  model.ReportStatusOpen,
+			committed:   []string{"create report"},
+		},
+		{
+			name:        "the threshold hides the review and closes its reports",
+			openReports: 2,
+			wantStatus:  model.ReportStatusActioned,
+			committed: []string{
+				"create report",
+				"set review 1 hidden true",
+				"audit review 1 hide",
+				"resolve reports of 1 as actioned by 0",
+			},
+		},
+		{
+			name:        "a failed audit record undoes the report and the hiding",
+			openReports: 2,
+			auditErr:    errAudit,
+			wantErr:     errAudit,
+		},
+		{
+			name:        "a hidden review is not hidden again",
+			openReports: 5,
+			hiddenAt:    &hiddenAt,
+			wantStatus:  model.ReportStatusOpen,
+			committed:   []string{"create report"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTransactor{}
+			reports := &txReportRepository{tx: tx, openReports: tt.openReports}
+			reviews := &hidingReviewRepository{tx: tx, review: model.Review{ID: 1, UserID: 7, HiddenAt: tt.hiddenAt}}
+			svc := NewReportService(reports, reviews, &txAuditService{tx: tx, err: tt.auditErr}, tx, 3, nil)
+
+			report, err := svc.ReportReview(context.Background(), 8, 1, &model.CreateReportRequest{Reason: model.ReportReasonSpam})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && report.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(tx.committed, tt.committed) {
+				t.Errorf("committed = %q, want %q", tx.committed, tt.committed)
+			}
+		})
+	}
+}
+
+func TestHideAndRestoreReview(t *testing.T) {
+	errAudit := errors.New("audit log unavailable")
+
+	tests := []struct {
+		name      string
+		call      func(s ReportService) error
+		auditErr  error
+		committed []string
+	}{
+		{
+			name: "hide",
+			call: func(s ReportService) error { return s.HideReview(context.Background(), 9, 1) },
+			committed: []string{
+				"set review 1 hidden true",
+				"audit review 1 hide",
+				"resolve reports of 1 as actioned by 9",
+			},
+		},
+		{
+			name:     "a failed audit record undoes the hiding",
+			call:     func(s ReportService) error { return s.HideReview(context.Background(), 9, 1) },
+			auditErr: errAudit,
+		},
+		{
+			name: "restore",
+			call: func(s ReportService) error { return s.RestoreReview(context.Background(), 9, 1) },
+			committed: []string{
+				"set review 1 hidden false",
+				"audit review 1 unhide",
+				"dismiss reports of 1 by 9",
+			},
+		},
+		{
+			name:     "a failed audit record undoes the restore",
+			call:     func(s ReportService) error { return s.RestoreReview(context.Background(), 9, 1) },
+			auditErr: errAudit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTransactor{}
+			reports := &txReportRepository{tx: tx}
+			reviews := &hidingReviewRepository{tx: tx, review: model.Review{ID: 1, UserID: 7}}
+			svc := NewReportService(reports, reviews, &txAuditService{tx: tx, err: tt.auditErr}, tx, 3, nil)
+
+			if err := tt.call(svc); !errors.Is(err, tt.auditErr) {
+				t.Fatalf("err = %v, want %v", err, tt.auditErr)
+			}
+			if !reflect.DeepEqual(tx.committed, tt.committed) {
+				t.Errorf("committed = %q, want %q", tx.committed, tt.committed)
+			}
+		})
+	}
+}
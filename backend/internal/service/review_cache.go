@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"protein-web-backend/internal/cache"
+	"protein-web-backend/internal/logging"
+)
+
+const reviewGenerationKey = "reviews:generation"
+
+// ReviewCache holds single reviews and feed pages read by ReviewService.
+//
+// Every key embeds a generation token. A write that can change what readers
+// see replaces the token, which invalidates all cached reviews and pages at
+// once without having to know their keys; entries of old generations are
+// never read again and expire through their TTL. Pages loaded from a
+// lagging read replica may still be cached for up to one TTL.
+//
+// A nil *ReviewCache disables caching.
+type ReviewCache struct {
+	loader *cache.Loader
+	ttl    time.Duration
+}
+
+func NewReviewCache(c cache.Cache, ttl time.Duration) *ReviewCache {
+	return &ReviewCache{loader: cache.NewLoader(c), ttl: ttl}
+}
+
+// Invalidate drops every cached review and page. Failures are logged: the
+// write that triggered it has already happened, and stale entries still
+// expire.
+func (c *ReviewCache) Invalidate(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.newGeneration(ctx)
+}
+
+func (c *ReviewCache) generation(ctx context.Context) (string, bool) {
+	token, ok, err := c.loader.Cache().Get(ctx, reviewGenerationKey)
+	if err != nil {
+		logging.FromContext(ctx).Warn("cache read failed", "key", reviewGenerationKey, "error", err)
+		return "", false
+	}
+	if ok {
+		return string(token), true
+	}
+	// The token was never set or was evicted. Reusing an earlier one could
+	// resurrect entries cached before the last invalidation.
+	return c.newGeneration(ctx)
+}
+
+func (c *ReviewCache) newGeneration(ctx context.Context) (string, bool) {
+	b := make([]byte, 8)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+	if err := c.loader.Cache().Set(ctx, reviewGenerationKey, []byte(token), 0); err != nil {
+		logging.FromContext(ctx).Warn("cache invalidation failed", "error", err)
+		return "", false
+	}
+	return token, true
+}
+
+// cached returns the value stored under key in the current generation, or
+// calls load and stores its result. Without a usable cache it just loads.
+func cached[T any](ctx context.Context, c *ReviewCache, key string, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	generation, ok := c.generation(ctx)
+	if !ok {
+		return load(ctx)
+	}
+
+	data, err := c.loader.Load(ctx, fmt.Sprintf("reviews:%s:%s", generation, key), c.ttl, func(ctx context.Context) ([]byte, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+	var value T
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to decode cached %s: %w", key, err)
+	}
+	return value, nil
+}
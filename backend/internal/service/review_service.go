@@ -1,36 +1,83 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
 	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/moderation"
 	"protein-web-backend/internal/repository"
 )
 
 type ReviewService interface {
-	CreateReview(userID int, req *model.CreateReviewRequest) (*model.Review, error)
-	GetReview(id int) (*model.Review, error)
-	GetAllReviews(limit, offset int) ([]*model.Review, error)
-	GetUserReviews(userID int, limit, offset int) ([]*model.Review, error)
+	CreateReview(ctx context.Context, userID int, req *model.CreateReviewRequest) (*model.Review, error)
+	// Read methods take the viewer's user ID (0 for anonymous) because hidden
+	// reviews remain visible to their author.
+	GetReview(ctx context.Context, id, viewerID int) (*model.Review, error)
+	GetAllReviews(ctx context.Context, viewerID, limit, offset int) ([]*model.Review, error)
+	GetUserReviews(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error)
+	// UpdateReview may only be called by the author. Like the deletes it
+	// fails with PreconditionFailedError unless the review is at a version
+	// ifMatch accepts.
+	UpdateReview(ctx context.Context, actorID, id int, ifMatch Precondition, req *model.UpdateReviewRequest) (*model.Review, error)
+	// DeleteReview and DeleteReviewImage may be called by the author or an admin
+	DeleteReview(ctx context.Context, actorID, id int, ifMatch Precondition) error
+	DeleteReviewImage(ctx context.Context, actorID, reviewID, imageID int, ifMatch Precondition) error
+	RestoreReview(ctx context.Context, actorID, id int) error
+}
+
+// Precondition holds the review versions named by an If-Match header. Any
+// accepts every version, like "If-Match: *".
+type Precondition struct {
+	Any      bool
+	Versions []int
+}
+
+func (p Precondition) check(version int) error {
+	if p.Any || slices.Contains(p.Versions, version) {
+		return nil
+	}
+	return &PreconditionFailedError{Message: "review has been modified since it was read"}
 }
 
 type reviewService struct {
 	reviewRepo repository.ReviewRepository
 	userRepo   repository.UserRepository
+	moderation ModerationService
+	audit      AuditService
+	tx         repository.Transactor
+	outbox     repository.OutboxRepository
+	cache      *ReviewCache
 }
 
-func NewReviewService(reviewRepo repository.ReviewRepository, userRepo repository.UserRepository) ReviewService {
+func NewReviewService(reviewRepo repository.ReviewRepository, userRepo repository.UserRepository, moderation ModerationService, audit AuditService, tx repository.Transactor, outbox repository.OutboxRepository, cache *ReviewCache) ReviewService {
 	return &reviewService{
 		reviewRepo: reviewRepo,
 		userRepo:   userRepo,
+		moderation: moderation,
+		audit:      audit,
+		tx:         tx,
+		outbox:     outbox,
+		cache:      cache,
 	}
 }
 
-func (s *reviewService) CreateReview(userID int, req *model.CreateReviewRequest) (*model.Review, error) {
+func (s *reviewService) CreateReview(ctx context.Context, userID int, req *model.CreateReviewRequest) (*model.Review, error) {
 	// Validate user exists
-	_, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user data: %w", translate(err))
+	}
+	if user == nil {
+		// The token outlived its account, which was deleted or erased
+		return nil, &UnauthorizedError{Message: "account no longer exists"}
+	}
+
+	// Run content filters; masked text replaces the comment
+	outcome, err := s.moderation.Screen(ctx, userID, "comment", req.Comment)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, err
 	}
 
 	// Create review
@@ -38,45 +85,90 @@ func (s *reviewService) CreateReview(userID int, req *model.CreateReviewRequest)
 		UserID:            userID,
 		ProteinPerServing: req.ProteinPerServing,
 		PricePerServing:   req.PricePerServing,
-		Comment:           req.Comment,
+		Comment:           outcome.Text,
 	}
 
-	err = s.reviewRepo.Create(review)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create review: %w", err)
-	}
+	// The review, its audit trail and the review.created event commit together
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Create(ctx, review); err != nil {
+			return fmt.Errorf("failed to create review: %w", err)
+		}
+		if err := s.audit.Record(ctx, userID, model.AuditEntityReview, review.ID, model.AuditActionCreate, nil, review); err != nil {
+			return err
+		}
 
-	// Create images
-	for i, imageURL := range req.Images {
-		image := &model.ReviewImage{
-			ReviewID:     review.ID,
-			ImageURL:     imageURL,
-			DisplayOrder: i,
+		// Flagged reviews stay hidden until a moderator restores them
+		hidden := outcome.Action == moderation.ActionFlag
+		if hidden {
+			if err := s.reviewRepo.SetHidden(ctx, review.ID, true); err != nil {
+				return err
+			}
+			if err := s.audit.Record(ctx, 0, model.AuditEntityReview, review.ID, model.AuditActionHide, flag("hidden", false), flag("hidden", true)); err != nil {
+				return err
+			}
 		}
-		err = s.reviewRepo.CreateImage(image)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create review image: %w", err)
+		if err := s.moderation.Record(ctx, userID, review.ID, req.Comment, outcome); err != nil {
+			return err
 		}
-		review.Images = append(review.Images, *image)
+
+		// Create images
+		for i, imageURL := range req.Images {
+			image := &model.ReviewImage{
+				ReviewID:     review.ID,
+				ImageURL:     imageURL,
+				DisplayOrder: i,
+			}
+			if err := s.reviewRepo.CreateImage(ctx, image); err != nil {
+				return fmt.Errorf("failed to create review image: %w", err)
+			}
+			if err := s.audit.Record(ctx, userID, model.AuditEntityReviewImage, image.ID, model.AuditActionCreate, nil, image); err != nil {
+				return err
+			}
+		}
+
+		return recordEvent(ctx, s.outbox, model.EventReviewCreated, model.AggregateReview, review.ID,
+			model.ReviewEventPayload{ReviewID: review.ID, UserID: userID, Hidden: hidden})
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.cache.Invalidate(ctx)
 
 	// Get full review with user data
-	fullReview, err := s.GetReview(review.ID)
+	return s.GetReview(ctx, review.ID, userID)
+}
+
+func (s *reviewService) GetReview(ctx context.Context, id, viewerID int) (*model.Review, error) {
+	// The cached review is the same for every viewer; visibility is
+	// checked on each read
+	review, err := cached(ctx, s.cache, fmt.Sprintf("review:%d", id), func(ctx context.Context) (*model.Review, error) {
+		return s.loadReview(ctx, id)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return fullReview, nil
+	if review.HiddenAt != nil && review.UserID != viewerID {
+		viewer, err := s.userRepo.GetByID(ctx, viewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get viewer: %w", err)
+		}
+		if !viewer.IsAdmin() {
+			return nil, &NotFoundError{Resource: "review"}
+		}
+	}
+
+	return review, nil
 }
 
-func (s *reviewService) GetReview(id int) (*model.Review, error) {
-	review, err := s.reviewRepo.GetByID(id)
+func (s *reviewService) loadReview(ctx context.Context, id int) (*model.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, translate(err)
 	}
 
 	// Get user data
-	user, err := s.userRepo.GetByID(review.UserID)
+	user, err := s.userRepo.GetByID(ctx, review.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user data: %w", err)
 	}
@@ -85,7 +177,7 @@ func (s *reviewService) GetReview(id int) (*model.Review, error) {
 	return review, nil
 }
 
-func (s *reviewService) GetAllReviews(limit, offset int) ([]*model.Review, error) {
+func (s *reviewService) GetAllReviews(ctx context.Context, viewerID, limit, offset int) ([]*model.Review, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -93,15 +185,14 @@ func (s *reviewService) GetAllReviews(limit, offset int) ([]*model.Review, error
 		offset = 0
 	}
 
-	reviews, err := s.reviewRepo.GetAll(limit, offset)
-	if err != nil {
-		return nil, err
-	}
-
-	return reviews, nil
+	// Pages differ per viewer because authors also see their hidden reviews
+	key := fmt.Sprintf("feed:%d:%d:%d", viewerID, limit, offset)
+	return cached(ctx, s.cache, key, func(ctx context.Context) ([]*model.Review, error) {
+		return s.reviewRepo.GetAll(ctx, viewerID, limit, offset)
+	})
 }
 
-func (s *reviewService) GetUserReviews(userID int, limit, offset int) ([]*model.Review, error) {
+func (s *reviewService) GetUserReviews(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -109,20 +200,182 @@ func (s *reviewService) GetUserReviews(userID int, limit, offset int) ([]*model.
 		offset = 0
 	}
 
-	reviews, err := s.reviewRepo.GetByUserID(userID, limit, offset)
+	key := fmt.Sprintf("user:%d:%d:%d:%d", userID, viewerID, limit, offset)
+	return cached(ctx, s.cache, key, func(ctx context.Context) ([]*model.Review, error) {
+		return s.loadUserReviews(ctx, userID, viewerID, limit, offset)
+	})
+}
+
+func (s *reviewService) loadUserReviews(ctx context.Context, userID, viewerID int, limit, offset int) ([]*model.Review, error) {
+	reviews, err := s.reviewRepo.GetByUserID(ctx, userID, viewerID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get user data for all reviews
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user data: %w", err)
 	}
+	if user == nil {
+		return nil, &NotFoundError{Resource: "user"}
+	}
 
 	for _, review := range reviews {
 		review.User = user
 	}
 
 	return reviews, nil
-}
\ No newline at end of file
+}
+
+func (s *reviewService) UpdateReview(ctx context.Context, actorID, id int, ifMatch Precondition, req *model.UpdateReviewRequest) (*model.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translate(err)
+	}
+	if review.UserID != actorID {
+		return nil, &ForbiddenError{Message: "only the author can edit this review"}
+	}
+	if err := ifMatch.check(review.Version); err != nil {
+		return nil, err
+	}
+
+	before := *review
+	if req.ProteinPerServing != nil {
+		review.ProteinPerServing = *req.ProteinPerServing
+	}
+	if req.PricePerServing != nil {
+		review.PricePerServing = *req.PricePerServing
+	}
+
+	// A new comment is screened like a new review; masked text replaces it
+	var outcome *moderation.Outcome
+	if req.Comment != nil {
+		outcome, err = s.moderation.Screen(ctx, actorID, "comment", *req.Comment)
+		if err != nil {
+			return nil, err
+		}
+		review.Comment = outcome.Text
+	}
+
+	// The change, its audit trail and the review.updated event commit
+	// together. The update only applies to the version checked above, so a
+	// concurrent write makes it fail instead of being overwritten.
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Update(ctx, review, before.Version); err != nil {
+			return translate(err)
+		}
+		if err := s.audit.Record(ctx, actorID, model.AuditEntityReview, id, model.AuditActionUpdate, &before, review); err != nil {
+			return err
+		}
+
+		hidden := review.HiddenAt != nil
+		if outcome != nil {
+			// Flagged text hides the review until a moderator restores it
+			if outcome.Action == moderation.ActionFlag && !hidden {
+				if err := s.reviewRepo.SetHidden(ctx, id, true); err != nil {
+					return err
+				}
+				if err := s.audit.Record(ctx, 0, model.AuditEntityReview, id, model.AuditActionHide, flag("hidden", false), flag("hidden", true)); err != nil {
+					return err
+				}
+				hidden = true
+			}
+			if err := s.moderation.Record(ctx, actorID, id, *req.Comment, outcome); err != nil {
+				return err
+			}
+		}
+
+		return recordEvent(ctx, s.outbox, model.EventReviewUpdated, model.AggregateReview, id,
+			model.ReviewEventPayload{ReviewID: id, UserID: review.UserID, Hidden: hidden})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx)
+
+	return s.GetReview(ctx, id, actorID)
+}
+
+func (s *reviewService) DeleteReview(ctx context.Context, actorID, id int, ifMatch Precondition) error {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return translate(err)
+	}
+	if err := s.authorizeAuthorOrAdmin(ctx, actorID, review); err != nil {
+		return err
+	}
+	if err := ifMatch.check(review.Version); err != nil {
+		return err
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.SoftDelete(ctx, id, review.Version); err != nil {
+			return translate(err)
+		}
+		if err := s.audit.Record(ctx, actorID, model.AuditEntityReview, id, model.AuditActionDelete, flag("deleted", false), flag("deleted", true)); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, model.EventReviewDeleted, model.AggregateReview, id,
+			model.ReviewEventPayload{ReviewID: id, UserID: review.UserID, Hidden: review.HiddenAt != nil})
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
+}
+
+func (s *reviewService) DeleteReviewImage(ctx context.Context, actorID, reviewID, imageID int, ifMatch Precondition) error {
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return translate(err)
+	}
+	if err := s.authorizeAuthorOrAdmin(ctx, actorID, review); err != nil {
+		return err
+	}
+	if err := ifMatch.check(review.Version); err != nil {
+		return err
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.DeleteImage(ctx, reviewID, imageID, review.Version); err != nil {
+			return translate(err)
+		}
+		return s.audit.Record(ctx, actorID, model.AuditEntityReviewImage, imageID, model.AuditActionDelete, flag("deleted", false), flag("deleted", true))
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
+}
+
+// RestoreReview brings back a soft-deleted review. Only admins may call it.
+func (s *reviewService) RestoreReview(ctx context.Context, actorID, id int) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Restore(ctx, id); err != nil {
+			return translate(err)
+		}
+		return s.audit.Record(ctx, actorID, model.AuditEntityReview, id, model.AuditActionRestore, flag("deleted", true), flag("deleted", false))
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
+}
+
+func (s *reviewService) authorizeAuthorOrAdmin(ctx context.Context, actorID int, review *model.Review) error {
+	if review.UserID == actorID {
+		return nil
+	}
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return fmt.Errorf("failed to get user data: %w", err)
+	}
+	if !actor.IsAdmin() {
+		return &ForbiddenError{Message: "only the author or an admin can modify this review"}
+	}
+	return nil
+}
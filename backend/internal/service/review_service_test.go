@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+func TestPreconditionCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Precondition
+		ok   bool
+	}{
+		{name: "matching version", p: Precondition{Versions: []int{3}}, ok: true},
+		{name: "one of several", p: Precondition{Versions: []int{2, 3}}, ok: true},
+		{name: "any", p: Precondition{Any: true}, ok: true},
+		{name: "stale version", p: Precondition{Versions: []int{2}}},
+		{name: "nothing usable", p: Precondition{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.check(3)
+			var failed *PreconditionFailedError
+			if tt.ok && err != nil || !tt.ok && !errors.As(err, &failed) {
+				t.Errorf("check = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+type txWritesKey struct{}
+
+// fakeTransactor keeps the writes made inside InTx until fn succeeds, and
+// drops them otherwise
+type fakeTransactor struct {
+	committed []string
+}
+
+func (t *fakeTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var writes []string
+	if err := fn(context.WithValue(ctx, txWritesKey{}, &writes)); err != nil {
+		return err
+	}
+	t.committed = append(t.committed, writes...)
+	return nil
+}
+
+// write records a write in the transaction of ctx, or commits it at once
+// outside of one
+func (t *fakeTransactor) write(ctx context.Context, op string) {
+	if writes, ok := ctx.Value(txWritesKey{}).(*[]string); ok {
+		*writes = append(*writes, op)
+		return
+	}
+	t.committed = append(t.committed, op)
+}
+
+type imageReviewRepository struct {
+	repository.ReviewRepository
+	tx     *fakeTransactor
+	review model.Review
+}
+
+func (r *imageReviewRepository) GetByID(ctx context.Context, id int) (*model.Review, error) {
+	review := r.review
+	return &review, nil
+}
+
+func (r *imageReviewRepository) DeleteImage(ctx context.Context, reviewID, imageID, version int) error {
+	if version != r.review.Version {
+		return repository.ErrReviewModified
+	}
+	r.tx.write(ctx, fmt.Sprintf("delete image %d", imageID))
+	return nil
+}
+
+type txAuditService struct {
+	AuditService
+	tx  *fakeTransactor
+	err error
+}
+
+func (s *txAuditService) Record(ctx context.Context, actorID int, entityType string, entityID int, action string, before, after interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.tx.write(ctx, fmt.Sprintf("audit %s %d %s", entityType, entityID, action))
+	return nil
+}
+
+func TestDeleteReviewImage(t *testing.T) {
+	errAudit := errors.New("audit log unavailable")
+
+	tests := []struct {
+		name      string
+		ifMatch   Precondition
+		auditErr  error
+		wantErr   error
+		committed []string
+	}{
+		{
+			name:      "the image and its audit record are written together",
+			ifMatch:   Precondition{Versions: []int{3}},
+			committed: []string{"delete image 5", "audit review_image 5 delete"},
+		},
+		{
+			name:     "a failed audit record undoes the delete",
+			ifMatch:  Precondition{Versions: []int{3}},
+			auditErr: errAudit,
+			wantErr:  errAudit,
+		},
+		{
+			name:    "a stale version writes nothing",
+			ifMatch: Precondition{Versions: []int{2}},
+			wantErr: &PreconditionFailedError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTransactor{}
+			reviews := &imageReviewRepository{tx: tx, review: model.Review{ID: 1, UserID: 7, Version: 3}}
+			svc := NewReviewService(reviews, nil, nil, &txAuditService{tx: tx, err: tt.auditErr}, tx, nil, nil)
+
+			err := svc.DeleteReviewImage(context.Background(), 7, 1, 5, tt.ifMatch)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+			case *PreconditionFailedError:
+				if !errors.As(err, &want) {
+					t.Fatalf("err = %v, want a PreconditionFailedError", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("err = %v, want %v", err, want)
+				}
+			}
+			if !reflect.DeepEqual(tx.committed, tt.committed) {
+				t.Errorf("committed = %q, want %q", tx.committed, tt.committed)
+			}
+		})
+	}
+}
+
+// lookupUserRepository returns user, or err when it is set
+type lookupUserRepository struct {
+	repository.UserRepository
+	user *model.User
+	err  error
+}
+
+func (r *lookupUserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return r.user, r.err
+}
+
+func TestCreateReviewAuthorLookup(t *testing.T) {
+	errDB := errors.New("connection refused")
+
+	tests := []struct {
+		name  string
+		users *lookupUserRepository
+		check func(err error) bool
+	}{
+		{
+			name:  "deleted account",
+			users: &lookupUserRepository{},
+			check: func(err error) bool {
+				var unauthorized *UnauthorizedError
+				return errors.As(err, &unauthorized)
+			},
+		},
+		{
+			name:  "missing user",
+			users: &lookupUserRepository{err: repository.ErrUserNotFound},
+			check: func(err error) bool {
+				var notFound *NotFoundError
+				return errors.As(err, &notFound)
+			},
+		},
+		{
+			name:  "database failure",
+			users: &lookupUserRepository{err: errDB},
+			check: func(err error) bool { return errors.Is(err, errDB) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewReviewService(nil, tt.users, nil, nil, nil, nil, nil)
+			_, err := svc.CreateReview(context.Background(), 7, &model.CreateReviewRequest{Comment: "good"})
+			if !tt.check(err) {
+				t.Errorf("err = %#v", err)
+			}
+		})
+	}
+}
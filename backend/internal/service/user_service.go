@@ -1,50 +1,63 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"os"
 	"regexp"
 	"strings"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"protein-web-backend/internal/auth"
 	"protein-web-backend/internal/model"
 	"protein-web-backend/internal/repository"
 )
 
+var (
+	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+	errInvalidCredentials = &UnauthorizedError{Message: "invalid email or password"}
+	errEmailTaken         = &ConflictError{Message: "user with this email already exists"}
+)
+
 type UserService interface {
-	GetUsers() ([]model.User, error)
-	RegisterUser(email, password, name string) (*model.User, error)
-	LoginUser(email, password string) (string, *model.User, error) // returns: token, user, error
+	GetUsers(ctx context.Context) ([]model.User, error)
+	RegisterUser(ctx context.Context, email, password, name string) (*model.User, error)
+	LoginUser(ctx context.Context, email, password string) (string, *model.User, error) // returns: token, user, error
+	DeleteUser(ctx context.Context, actorID, id int) error
+	RestoreUser(ctx context.Context, actorID, id int) error
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo   repository.UserRepository
+	audit  AuditService
+	tx     repository.Transactor
+	tokens *auth.Tokens
+	// reviews is invalidated when a user's reviews appear or disappear
+	reviews *ReviewCache
 }
 
-func NewUserService(r repository.UserRepository) UserService {
-	return &userService{repo: r}
+func NewUserService(r repository.UserRepository, audit AuditService, tx repository.Transactor, tokens *auth.Tokens, reviews *ReviewCache) UserService {
+	return &userService{repo: r, audit: audit, tx: tx, tokens: tokens, reviews: reviews}
 }
 
-func (s *userService) GetUsers() ([]model.User, error) {
-	return s.repo.GetAll()
+func (s *userService) GetUsers(ctx context.Context) ([]model.User, error) {
+	return s.repo.GetAll(ctx)
 }
 
 // RegisterUser creates a new user with validation and password hashing
-func (s *userService) RegisterUser(email, password, name string) (*model.User, error) {
+func (s *userService) RegisterUser(ctx context.Context, email, password, name string) (*model.User, error) {
 	// Validate input
 	if err := s.validateRegistrationInput(email, password); err != nil {
 		return nil, err
 	}
 
 	// Check if user already exists
-	existingUser, err := s.repo.GetByEmail(email)
+	existingUser, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	if existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, errEmailTaken
 	}
 
 	// Hash password
@@ -57,6 +70,7 @@ func (s *userService) RegisterUser(email, password, name string) (*model.User, e
 	user := &model.User{
 		Email:        email,
 		PasswordHash: string(hashedPassword),
+		Role:         model.RoleUser,
 	}
 	
 	// Set name if provided
@@ -64,67 +78,106 @@ func (s *userService) RegisterUser(email, password, name string) (*model.User, e
 		user.Name = &name
 	}
 
-	// Save to database
-	if err := s.repo.Create(user); err != nil {
+	// Save to database. The email of a soft-deleted account passes the
+	// check above but is still taken.
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return translate(err)
+		}
+		return s.audit.Record(ctx, user.ID, model.AuditEntityUser, user.ID, model.AuditActionCreate, nil, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
+// DeleteUser soft-deletes a user; their reviews disappear from listings
+func (s *userService) DeleteUser(ctx context.Context, actorID, id int) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.SoftDelete(ctx, id); err != nil {
+			return translate(err)
+		}
+		return s.audit.Record(ctx, actorID, model.AuditEntityUser, id, model.AuditActionDelete, flag("deleted", false), flag("deleted", true))
+	})
+	if err != nil {
+		return err
+	}
+	s.reviews.Invalidate(ctx)
+	return nil
+}
+
+// RestoreUser brings back a soft-deleted user
+func (s *userService) RestoreUser(ctx context.Context, actorID, id int) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return translate(err)
+		}
+		return s.audit.Record(ctx, actorID, model.AuditEntityUser, id, model.AuditActionRestore, flag("deleted", true), flag("deleted", false))
+	})
+	if err != nil {
+		return err
+	}
+	s.reviews.Invalidate(ctx)
+	return nil
+}
+
 // validateRegistrationInput validates email and password
 func (s *userService) validateRegistrationInput(email, password string) error {
+	var fields []FieldError
+
 	// Email validation
 	if strings.TrimSpace(email) == "" {
-		return errors.New("email is required")
-	}
-	
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
-		return errors.New("invalid email format")
+		fields = append(fields, FieldError{Field: "email", Message: "is required"})
+	} else if !emailRegex.MatchString(email) {
+		fields = append(fields, FieldError{Field: "email", Message: "has an invalid format"})
 	}
 
 	// Password validation
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters long")
-	}
-
-	// Check password complexity
 	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
 	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
 	hasDigit := regexp.MustCompile(`\d`).MatchString(password)
 
-	if !hasUpper || !hasLower || !hasDigit {
-		return errors.New("password must contain at least one uppercase letter, one lowercase letter, and one digit")
+	switch {
+	case strings.TrimSpace(password) == "":
+		fields = append(fields, FieldError{Field: "password", Message: "is required"})
+	case len(password) < 8:
+		fields = append(fields, FieldError{Field: "password", Message: "must be at least 8 characters long"})
+	case !hasUpper || !hasLower || !hasDigit:
+		fields = append(fields, FieldError{Field: "password", Message: "must contain at least one uppercase letter, one lowercase letter, and one digit"})
 	}
 
+	if len(fields) > 0 {
+		return &ValidationError{Message: "invalid registration", Fields: fields}
+	}
 	return nil
 }
 
 // LoginUser authenticates a user and returns a JWT token
-func (s *userService) LoginUser(email, password string) (string, *model.User, error) {
+func (s *userService) LoginUser(ctx context.Context, email, password string) (string, *model.User, error) {
 	// Validate input
 	if err := s.validateLoginInput(email, password); err != nil {
 		return "", nil, err
 	}
 
 	// Get user by email
-	user, err := s.repo.GetByEmail(email)
+	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return "", nil, err
 	}
 	if user == nil {
-		return "", nil, errors.New("invalid email or password")
+		return "", nil, errInvalidCredentials
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return "", nil, errors.New("invalid email or password")
+		return "", nil, errInvalidCredentials
 	}
 
 	// Generate JWT token
-	token, err := s.generateJWTToken(user)
+	token, err := s.tokens.Issue(user)
 	if err != nil {
 		return "", nil, errors.New("failed to generate token")
 	}
@@ -134,48 +187,21 @@ func (s *userService) LoginUser(email, password string) (string, *model.User, er
 
 // validateLoginInput validates email and password for login
 func (s *userService) validateLoginInput(email, password string) error {
+	var fields []FieldError
+
 	if strings.TrimSpace(email) == "" {
-		return errors.New("email is required")
+		fields = append(fields, FieldError{Field: "email", Message: "is required"})
+	} else if !emailRegex.MatchString(email) {
+		// Basic email format validation
+		fields = append(fields, FieldError{Field: "email", Message: "has an invalid format"})
 	}
 
 	if strings.TrimSpace(password) == "" {
-		return errors.New("password is required")
+		fields = append(fields, FieldError{Field: "password", Message: "is required"})
 	}
 
-	// Basic email format validation
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
-		return errors.New("invalid email format")
+	if len(fields) > 0 {
+		return &ValidationError{Message: "invalid login", Fields: fields}
 	}
-
 	return nil
 }
-
-// generateJWTToken creates a JWT token for the user
-func (s *userService) generateJWTToken(user *model.User) (string, error) {
-	// Get JWT secret from environment variable
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-secret-key" // Default for development (should be in .env)
-	}
-
-	// Create JWT claims
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"name":    user.Name,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(), // 24 hours expiration
-		"iat":     time.Now().Unix(),
-	}
-
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
-}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+)
+
+// txUserRepository has one user, 7, who is soft-deleted when deleted is
+// set; the email taken@example.com belongs to another soft-deleted account
+type txUserRepository struct {
+	repository.UserRepository
+	tx      *fakeTransactor
+	deleted bool
+}
+
+func (r *txUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return nil, nil // soft-deleted accounts are not returned
+}
+
+func (r *txUserRepository) Create(ctx context.Context, user *model.User) error {
+	if user.Email == "taken@example.com" {
+		return repository.ErrEmailTaken
+	}
+	user.ID = 8
+	r.tx.write(ctx, "create user")
+	return nil
+}
+
+func (r *txUserRepository) SoftDelete(ctx context.Context, id int) error {
+	if id != 7 || r.deleted {
+		return repository.ErrUserNotFound
+	}
+	r.tx.write(ctx, fmt.Sprintf("delete user %d", id))
+	return nil
+}
+
+func (r *txUserRepository) Restore(ctx context.Context, id int) error {
+	if id != 7 || !r.deleted {
+		return repository.ErrUserNotFound
+	}
+	r.tx.write(ctx, fmt.Sprintf("restore user %d", id))
+	return nil
+}
+
+func TestUserServiceWritesWithAudit(t *testing.T) {
+	errAudit := errors.New("audit log unavailable")
+
+	tests := []struct {
+		name      string
+		deleted   bool
+		auditErr  error
+		call      func(s UserService) error
+		wantErr   error
+		committed []string
+	}{
+		{
+			name: "register",
+			call: func(s UserService) error {
+				_, err := s.RegisterUser(context.Background(), "new@example.com", "Password1", "")
+				return err
+			},
+			committed: []string{"create user", "audit user 8 create"},
+		},
+		{
+			name:     "a failed audit record undoes the registration",
+			auditErr: errAudit,
+			call: func(s UserService) error {
+				_, err := s.RegisterUser(context.Background(), "new@example.com", "Password1", "")
+				return err
+			},
+			wantErr: errAudit,
+		},
+		{
+			name: "the email of a soft-deleted account is a conflict",
+			call: func(s UserService) error {
+				_, err := s.RegisterUser(context.Background(), "taken@example.com", "Password1", "")
+				return err
+			},
+			wantErr: errEmailTaken,
+		},
+		{
+			name:      "delete",
+			call:      func(s UserService) error { return s.DeleteUser(context.Background(), 1, 7) },
+			committed: []string{"delete user 7", "audit user 7 delete"},
+		},
+		{
+			name:     "a failed audit record undoes the delete",
+			auditErr: errAudit,
+			call:     func(s UserService) error { return s.DeleteUser(context.Background(), 1, 7) },
+			wantErr:  errAudit,
+		},
+		{
+			name:      "restore",
+			deleted:   true,
+			call:      func(s UserService) error { return s.RestoreUser(context.Background(), 1, 7) },
+			committed: []string{"restore user 7", "audit user 7 restore"},
+		},
+		{
+			name:     "a failed audit record undoes the restore",
+			deleted:  true,
+			auditErr: errAudit,
+			call:     func(s UserService) error { return s.RestoreUser(context.Background(), 1, 7) },
+			wantErr:  errAudit,
+		},
+		{
+			name:    "restore of a user that is not deleted",
+			call:    func(s UserService) error { return s.RestoreUser(context.Background(), 1, 7) },
+			wantErr: &NotFoundError{Resource: "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTransactor{}
+			users := &txUserRepository{tx: tx, deleted: tt.deleted}
+			svc := NewUserService(users, &txAuditService{tx: tx, err: tt.auditErr}, tx, nil, nil)
+
+			err := tt.call(svc)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+			case *NotFoundError, *ConflictError:
+				if !reflect.DeepEqual(err, want) {
+					t.Fatalf("err = %#v, want %#v", err, want)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("err = %v, want %v", err, want)
+				}
+			}
+			if !reflect.DeepEqual(tx.committed, tt.committed) {
+				t.Errorf("committed = %q, want %q", tx.committed, tt.committed)
+			}
+		})
+	}
+}
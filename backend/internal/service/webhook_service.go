@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"protein-web-backend/internal/jobs"
+	"protein-web-backend/internal/logging"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+	"protein-web-backend/internal/webhook"
+)
+
+// JobWebhookDeliver is the job type that delivers one event to one webhook
+const JobWebhookDeliver = "webhooks.deliver"
+
+type WebhookService interface {
+	// CreateWebhook returns the webhook with its secret; later reads omit it
+	CreateWebhook(ctx context.Context, actorID int, req *model.CreateWebhookRequest) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context, limit, offset int) ([]*model.Webhook, error)
+	GetWebhook(ctx context.Context, id int) (*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, actorID, id int, req *model.UpdateWebhookRequest) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, actorID, id int) error
+	ListDeliveries(ctx context.Context, id, limit, offset int) ([]*model.WebhookDelivery, error)
+	// PingWebhook sends a test event right away, even to an inactive
+	// webhook. Its outcome is logged but does not count towards disabling.
+	// A failed send is not an error: the returned delivery reports it in
+	// Success, StatusCode and Error.
+	PingWebhook(ctx context.Context, id int) (*model.WebhookDelivery, error)
+
+	// Dispatch enqueues a delivery job per webhook subscribed to the event
+	Dispatch(ctx context.Context, event *model.OutboxEvent) error
+	// Deliver runs a JobWebhookDeliver job
+	Deliver(ctx context.Context, job model.WebhookJob) error
+}
+
+type webhookService struct {
+	repo         repository.WebhookRepository
+	audit        AuditService
+	queue        *jobs.Queue
+	sender       *webhook.Sender
+	disableAfter int
+}
+
+// NewWebhookService creates a WebhookService. A webhook is disabled after
+// disableAfter consecutive failed deliveries.
+func NewWebhookService(repo repository.WebhookRepository, audit AuditService, queue *jobs.Queue, sender *webhook.Sender, disableAfter int) WebhookService {
+	return &webhookService{
+		repo:         repo,
+		audit:        audit,
+		queue:        queue,
+		sender:       sender,
+		disableAfter: disableAfter,
+	}
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, actorID int, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	if err := checkWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	hook := &model.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: uniqueStrings(req.EventTypes),
+		Active:     true,
+	}
+	if hook.Secret == "" {
+		hook.Secret = newWebhookSecret()
+	}
+
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+	if err := s.audit.Record(ctx, actorID, model.AuditEntityWebhook, hook.ID, model.AuditActionCreate, nil, redacted(hook)); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context, limit, offset int) ([]*model.Webhook, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	hooks, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i, hook := range hooks {
+		hooks[i] = redacted(hook)
+	}
+	return hooks, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id int) (*model.Webhook, error) {
+	hook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translate(err)
+	}
+	return redacted(hook), nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, actorID, id int, req *model.UpdateWebhookRequest) (*model.Webhook, error) {
+	hook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translate(err)
+	}
+	before := redacted(hook)
+
+	if req.URL != nil {
+		if err := checkWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		hook.EventTypes = uniqueStrings(*req.EventTypes)
+	}
+	if req.Active != nil && *req.Active != hook.Active {
+		hook.Active = *req.Active
+		if hook.Active {
+			// A re-enabled webhook gets a fresh run of attempts
+			hook.ConsecutiveFailures = 0
+			hook.DisabledAt = nil
+		} else {
+			now := time.Now()
+			hook.DisabledAt = &now
+		}
+	}
+	if req.RotateSecret {
+		hook.Secret = newWebhookSecret()
+	}
+
+	if err := s.repo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	if err := s.audit.Record(ctx, actorID, model.AuditEntityWebhook, id, model.AuditActionUpdate, before, redacted(hook)); err != nil {
+		return nil, err
+	}
+
+	if !req.RotateSecret {
+		return redacted(hook), nil
+	}
+	return hook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, actorID, id int) error {
+	hook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return translate(err)
+	}
+	// Queued deliveries find the webhook gone and are dropped
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return translate(err)
+	}
+	return s.audit.Record(ctx, actorID, model.AuditEntityWebhook, id, model.AuditActionDelete, redacted(hook), nil)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, id, limit, offset int) ([]*model.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, translate(err)
+	}
+	return s.repo.ListDeliveries(ctx, id, limit, offset)
+}
+
+func (s *webhookService) PingWebhook(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	hook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	payload, err := json.Marshal(map[string]int{"webhookId": id})
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 16)
+	rand.Read(key)
+	event := &model.OutboxEvent{
+		Key:           hex.EncodeToString(key),
+		Type:          model.EventWebhookPing,
+		AggregateType: model.AuditEntityWebhook,
+		AggregateID:   id,
+		Payload:       payload,
+		CreatedAt:     time.Now(),
+	}
+
+	delivery, sendErr := s.send(ctx, hook, event, 1)
+	if sendErr != nil {
+		logging.FromContext(ctx).Warn("webhook ping failed", "webhook_id", hook.ID, "error", sendErr)
+	}
+	if _, err := s.repo.RecordDelivery(ctx, delivery, 0); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *webhookService) Dispatch(ctx context.Context, event *model.OutboxEvent) error {
+	hooks, err := s.repo.ListSubscribed(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		// The key makes a redelivered event enqueue nothing new
+		key := fmt.Sprintf("webhook:%d:%s", hook.ID, event.Key)
+		if _, err := s.queue.Enqueue(ctx, JobWebhookDeliver, model.WebhookJob{WebhookID: hook.ID, Event: event}, jobs.Unique(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *webhookService) Deliver(ctx context.Context, job model.WebhookJob) error {
+	if job.Event == nil {
+		return jobs.Permanent(errors.New("job has no event"))
+	}
+	hook, err := s.repo.GetByID(ctx, job.WebhookID)
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Changed since the job was enqueued
+	if !hook.Active || !hook.Subscribes(job.Event.Type) {
+		return nil
+	}
+
+	delivery, sendErr := s.send(ctx, hook, job.Event, jobs.Attempt(ctx))
+	disabled, err := s.repo.RecordDelivery(ctx, delivery, s.disableAfter)
+	if err != nil {
+		// Retrying a successful delivery just to log it would send it twice
+		logging.FromContext(ctx).Error("failed to record webhook delivery", "webhook_id", hook.ID, "error", err)
+	}
+	if disabled {
+		logging.FromContext(ctx).Warn("webhook disabled after repeated failures", "webhook_id", hook.ID, "failures", s.disableAfter)
+		if err := s.audit.Record(ctx, 0, model.AuditEntityWebhook, hook.ID, model.AuditActionUpdate, flag("active", true), flag("active", false)); err != nil {
+			logging.FromContext(ctx).Error("failed to audit webhook disabling", "webhook_id", hook.ID, "error", err)
+		}
+		return jobs.Permanent(sendErr)
+	}
+	return sendErr
+}
+
+// send delivers event and describes the outcome; the error is also in the
+// returned delivery
+func (s *webhookService) send(ctx context.Context, hook *model.Webhook, event *model.OutboxEvent, attempt int) (*model.WebhookDelivery, error) {
+	start := time.Now()
+	status, err := s.sender.Send(ctx, hook.URL, hook.Secret, event)
+
+	delivery := &model.WebhookDelivery{
+		WebhookID:  hook.ID,
+		EventID:    event.Key,
+		EventType:  event.Type,
+		Attempt:    attempt,
+		Success:    err == nil,
+		DurationMs: int(time.Since(start).Milliseconds()),
+	}
+	if status != 0 {
+		delivery.StatusCode = &status
+	}
+	if err != nil {
+		message := err.Error()
+		delivery.Error = &message
+	}
+	return delivery, err
+}
+
+// checkWebhookURL rejects the root-relative paths the url rule also allows
+func checkWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewFieldError("url", "must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// redacted returns a copy of hook without its secret
+func redacted(hook *model.Webhook) *model.Webhook {
+	c := *hook
+	c.Secret = ""
+	return &c
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
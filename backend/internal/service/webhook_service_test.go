@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/jobs"
+	"protein-web-backend/internal/model"
+	"protein-web-backend/internal/repository"
+	"protein-web-backend/internal/webhook"
+)
+
+// memoryJobRepository runs jobs in insertion order and ignores delays, so
+// retries happen at once
+type memoryJobRepository struct {
+	mu   sync.Mutex
+	jobs []*model.Job
+}
+
+func (r *memoryJobRepository) Enqueue(ctx context.Context, job *model.Job, delay time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.jobs {
+		if job.UniqueKey != nil && j.UniqueKey != nil && *j.UniqueKey == *job.UniqueKey {
+			return false, nil
+		}
+	}
+	job.ID = int64(len(r.jobs) + 1)
+	job.Status = model.JobPending
+	r.jobs = append(r.jobs, job)
+	return true, nil
+}
+
+func (r *memoryJobRepository) Claim(ctx context.Context, workerID string, lease time.Duration) (*model.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.jobs {
+		if j.Status == model.JobPending {
+			j.Status = model.JobRunning
+			j.Attempts++
+			claimed := *j
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryJobRepository) finish(id int64, status model.JobStatus, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j := r.jobs[id-1]
+	j.Status = status
+	if lastError != "" {
+		j.LastError = &lastError
+	}
+	return nil
+}
+
+func (r *memoryJobRepository) Complete(ctx context.Context, id int64, workerID string) error {
+	return r.finish(id, model.JobDone, "")
+}
+
+func (r *memoryJobRepository) Retry(ctx context.Context, id int64, workerID string, delay time.Duration, lastError string) error {
+	return r.finish(id, model.JobPending, lastError)
+}
+
+func (r *memoryJobRepository) Bury(ctx context.Context, id int64, workerID string, lastError string) error {
+	return r.finish(id, model.JobDead, lastError)
+}
+
+func (r *memoryJobRepository) PurgeFinished(ctx context.Context, age time.Duration) (int64, error) {
+	return 0, nil
+}
+
+// finished returns the status of the first job once it is done or dead
+func (r *memoryJobRepository) finished() (model.JobStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.jobs) == 0 {
+		return "", false
+	}
+	status := r.jobs[0].Status
+	return status, status == model.JobDone || status == model.JobDead
+}
+
+// memoryWebhookRepository counts failures and disables webhooks like the
+// MySQL repository
+type memoryWebhookRepository struct {
+	repository.WebhookRepository
+
+	mu         sync.Mutex
+	hook       model.Webhook
+	deliveries []*model.WebhookDelivery
+}
+
+func (r *memoryWebhookRepository) GetByID(ctx context.Context, id int) (*model.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.hook.ID {
+		return nil, repository.ErrWebhookNotFound
+	}
+	hook := r.hook
+	return &hook, nil
+}
+
+func (r *memoryWebhookRepository) ListSubscribed(ctx context.Context, eventType string) ([]*model.Webhook, error) {
+	hook, _ := r.GetByID(ctx, r.hook.ID)
+	if !hook.Active || !hook.Subscribes(eventType) {
+		return nil, nil
+	}
+	return []*model.Webhook{hook}, nil
+}
+
+func (r *memoryWebhookRepository) RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery, disableAfter int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery.ID = int64(len(r.deliveries) + 1)
+	r.deliveries = append(r.deliveries, delivery)
+
+	if disableAfter <= 0 {
+		return false, nil
+	}
+	if delivery.Success {
+		r.hook.ConsecutiveFailures = 0
+		return false, nil
+	}
+	r.hook.ConsecutiveFailures++
+	if r.hook.Active && r.hook.ConsecutiveFailures >= disableAfter {
+		now := time.Now()
+		r.hook.Active, r.hook.DisabledAt = false, &now
+		return true, nil
+	}
+	return false, nil
+}
+
+type recordingAuditService struct {
+	AuditService
+
+	mu      sync.Mutex
+	entries []string
+}
+
+func (s *recordingAuditService) Record(ctx context.Context, actorID int, entityType string, entityID int, action string, before, after interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, _ := json.Marshal(before)
+	a, _ := json.Marshal(after)
+	s.entries = append(s.entries, entityType+" "+action+" "+string(b)+" -> "+string(a))
+	return nil
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // the receiver's answers; the last one repeats
+		maxAttempts  int
+		disableAfter int
+
+		wantStatuses []int
+		wantJob      model.JobStatus
+		wantActive   bool
+		wantFailures int
+		wantAudit    []string
+	}{
+		{
+			name:         "5xx is retried until delivered",
+			statuses:     []int{500, 502, 204},
+			maxAttempts:  5,
+			disableAfter: 10,
+			wantStatuses: []int{500, 502, 204},
+			wantJob:      model.JobDone,
+			wantActive:   true,
+			wantFailures: 0,
+		},
+		{
+			name:         "the job gives up after its attempts",
+			statuses:     []int{503},
+			maxAttempts:  2,
+			disableAfter: 10,
+			wantStatuses: []int{503, 503},
+			wantJob:      model.JobDead,
+			wantActive:   true,
+			wantFailures: 2,
+		},
+		{
+			name:         "the webhook is disabled at the failure threshold",
+			statuses:     []int{500},
+			maxAttempts:  10,
+			disableAfter: 3,
+			wantStatuses: []int{500, 500, 500},
+			wantJob:      model.JobDead,
+			wantActive:   false,
+			wantFailures: 3,
+			wantAudit:    []string{`webhook update {"active":true} -> {"active":false}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &model.OutboxEvent{
+				Key:         "event-1",
+				Type:        model.EventReviewCreated,
+				AggregateID: 7,
+				Payload:     json.RawMessage(`{"id":7}`),
+			}
+
+			var mu sync.Mutex
+			var received int
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if err := webhook.Verify("secret", r.Header, body, time.Minute); err != nil {
+					t.Errorf("signature: %v", err)
+				}
+				// Retries carry the same event ID so receivers can deduplicate
+				if got := r.Header.Get(webhook.HeaderID); got != event.Key {
+					t.Errorf("%s = %q, want %q", webhook.HeaderID, got, event.Key)
+				}
+				mu.Lock()
+				status := tt.statuses[min(received, len(tt.statuses)-1)]
+				received++
+				mu.Unlock()
+				w.WriteHeader(status)
+			}))
+			defer receiver.Close()
+
+			hooks := &memoryWebhookRepository{hook: model.Webhook{
+				ID:         1,
+				URL:        receiver.URL,
+				Secret:     "secret",
+				EventTypes: []string{model.EventReviewCreated},
+				Active:     true,
+			}}
+			audit := &recordingAuditService{}
+			jobRepo := &memoryJobRepository{}
+			queue := jobs.NewQueue(jobRepo, tt.maxAttempts)
+			svc := NewWebhookService(hooks, audit, queue, webhook.NewSender(time.Second), tt.disableAfter)
+
+			registry := jobs.NewRegistry()
+			jobs.Register(registry, JobWebhookDeliver, svc.Deliver)
+			worker := jobs.NewWorker(jobRepo, queue, registry, jobs.WorkerOptions{
+				Concurrency:  1,
+				PollInterval: time.Millisecond,
+				Lease:        5 * time.Second,
+				BackoffBase:  time.Millisecond,
+				BackoffMax:   time.Millisecond,
+			})
+
+			ctx := context.Background()
+			if err := svc.Dispatch(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+			stop := worker.Start(ctx)
+			deadline := time.Now().Add(5 * time.Second)
+			status, done := jobRepo.finished()
+			for !done && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+				status, done = jobRepo.finished()
+			}
+			stop(time.Second)
+
+			if status != tt.wantJob {
+				t.Fatalf("job status = %q, want %q", status, tt.wantJob)
+			}
+
+			if len(hooks.deliveries) != len(tt.wantStatuses) {
+				t.Fatalf("logged %d deliveries, want %d", len(hooks.deliveries), len(tt.wantStatuses))
+			}
+			for i, d := range hooks.deliveries {
+				last := i == len(hooks.deliveries)-1
+				wantSuccess := tt.wantJob == model.JobDone && last
+				if d.Attempt != i+1 || d.StatusCode == nil || *d.StatusCode != tt.wantStatuses[i] || d.Success != wantSuccess {
+					t.Errorf("delivery %d = attempt %d, status %v, success %v", i, d.Attempt, d.StatusCode, d.Success)
+				}
+				if d.WebhookID != 1 || d.EventID != event.Key || d.EventType != event.Type {
+					t.Errorf("delivery %d = webhook %d, event %q %q", i, d.WebhookID, d.EventID, d.EventType)
+				}
+				if (d.Error != nil) == d.Success {
+					t.Errorf("delivery %d: success %v with error %v", i, d.Success, d.Error)
+				}
+			}
+
+			hook := hooks.hook
+			if hook.Active != tt.wantActive || (hook.DisabledAt != nil) == tt.wantActive {
+				t.Errorf("active = %v, disabledAt = %v, want active %v", hook.Active, hook.DisabledAt, tt.wantActive)
+			}
+			if hook.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("consecutive failures = %d, want %d", hook.ConsecutiveFailures, tt.wantFailures)
+			}
+			if len(audit.entries) != len(tt.wantAudit) {
+				t.Fatalf("audit = %q, want %q", audit.entries, tt.wantAudit)
+			}
+			for i := range audit.entries {
+				if audit.entries[i] != tt.wantAudit[i] {
+					t.Errorf("audit[%d] = %q, want %q", i, audit.entries[i], tt.wantAudit[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookDeliverySkipsDisabledWebhook(t *testing.T) {
+	var received int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer receiver.Close()
+
+	hooks := &memoryWebhookRepository{hook: model.Webhook{
+		ID:         1,
+		URL:        receiver.URL,
+		Secret:     "secret",
+		EventTypes: []string{model.EventReviewCreated},
+	}}
+	svc := NewWebhookService(hooks, &recordingAuditService{}, nil, webhook.NewSender(time.Second), 3)
+
+	job := model.WebhookJob{WebhookID: 1, Event: &model.OutboxEvent{Key: "event-1", Type: model.EventReviewCreated}}
+	if err := svc.Deliver(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	if received != 0 || len(hooks.deliveries) != 0 {
+		t.Errorf("inactive webhook got %d requests and %d log entries", received, len(hooks.deliveries))
+	}
+}
+
+func TestPingWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int // 0 closes the connection
+		wantSuccess bool
+		wantStatus  int
+		wantError   bool
+	}{
+		{name: "accepted", status: http.StatusNoContent, wantSuccess: true, wantStatus: http.StatusNoContent},
+		{name: "refused", status: http.StatusInternalServerError, wantStatus: http.StatusInternalServerError, wantError: true},
+		{name: "unreachable", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status == 0 {
+					panic(http.ErrAbortHandler)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			hooks := &memoryWebhookRepository{hook: model.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}}
+			svc := NewWebhookService(hooks, &recordingAuditService{}, nil, webhook.NewSender(time.Second), 3)
+
+			delivery, err := svc.PingWebhook(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("PingWebhook = %v; a failed send belongs in the delivery", err)
+			}
+			if delivery.Success != tt.wantSuccess || (delivery.Error != nil) != tt.wantError {
+				t.Errorf("delivery = %+v, want success %v, error %v", delivery, tt.wantSuccess, tt.wantError)
+			}
+			status := 0
+			if delivery.StatusCode != nil {
+				status = *delivery.StatusCode
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status code = %d, want %d", status, tt.wantStatus)
+			}
+			if len(hooks.deliveries) != 1 || hooks.deliveries[0] != delivery || hooks.hook.ConsecutiveFailures != 0 {
+				t.Errorf("recorded %d deliveries, %d failures; want the ping alone, not counted", len(hooks.deliveries), hooks.hook.ConsecutiveFailures)
+			}
+		})
+	}
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		svc := NewWebhookService(&memoryWebhookRepository{}, nil, nil, webhook.NewSender(time.Second), 3)
+		_, err := svc.PingWebhook(context.Background(), 2)
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("err = %v, want a NotFoundError", err)
+		}
+	})
+}
@@ -1,11 +1,23 @@
 package types
 
-// Common HTTP response types shared across all handlers
-type ErrorResponse struct {
-	Error string `json:"error"`
+// Problem is an RFC 9457 problem details body. Every error response uses it
+// and is sent as application/problem+json.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why one input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 type SuccessResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
-}
\ No newline at end of file
+}
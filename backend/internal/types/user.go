@@ -1,10 +1,12 @@
 package types
 
+import "time"
+
 // User-specific HTTP request/response types
 type RegisterUserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name,omitempty"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Name     string `json:"name,omitempty" validate:"max=100"`
 }
 
 type RegisterUserResponse struct {
@@ -15,8 +17,8 @@ type RegisterUserResponse struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -31,4 +33,18 @@ type UserInfo struct {
 	ID    int     `json:"id"`
 	Email string  `json:"email"`
 	Name  *string `json:"name"`
-}
\ No newline at end of file
+}
+
+// PublicUser is a user as listed to anyone. The role is left out so that
+// anonymous callers cannot tell which accounts are admins.
+type PublicUser struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	Name      *string   `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type EraseAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
@@ -0,0 +1,199 @@
+// Package validate checks decoded request structs against their `validate`
+// struct tags.
+//
+// Supported rules, separated by commas:
+//
+//	required   string must be non-blank, slice non-empty, number non-zero
+//	min=N      minimum rune length (string), element count (slice) or value (number)
+//	max=N      maximum rune length (string), element count (slice) or value (number)
+//	email      string must look like an email address
+//	url        string must be an absolute http(s) URL or a root-relative path
+//	oneof=a b  string must be one of the space separated values
+//	dive       the rules after it apply to each element of a slice
+//
+// Empty optional values skip every rule except required. Pointer fields are
+// optional as a whole: nil skips every rule, otherwise the rules apply to the
+// value pointed to, which suits partial updates.
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"protein-web-backend/internal/types"
+)
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// Struct validates v, which must be a struct or a pointer to one, and returns
+// one error per failing field. Field names use the JSON names so clients can
+// map them back onto their form.
+func Struct(v interface{}) []types.FieldError {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: expected struct, got %s", rv.Kind()))
+	}
+
+	var errs []types.FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		errs = append(errs, checkField(jsonName(field), rv.Field(i), strings.Split(tag, ","))...)
+	}
+	return errs
+}
+
+func checkField(name string, v reflect.Value, rules []string) []types.FieldError {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	for i, rule := range rules {
+		if rule == "dive" {
+			if v.Kind() != reflect.Slice {
+				panic("validate: dive on non-slice field " + name)
+			}
+			var errs []types.FieldError
+			for j := 0; j < v.Len(); j++ {
+				errs = append(errs, checkField(fmt.Sprintf("%s[%d]", name, j), v.Index(j), rules[i+1:])...)
+			}
+			return errs
+		}
+
+		key, param, _ := strings.Cut(rule, "=")
+		if key != "required" && isZero(v) {
+			continue
+		}
+		if msg := check(key, param, v); msg != "" {
+			// Report only the first failing rule per field
+			return []types.FieldError{{Field: name, Message: msg}}
+		}
+	}
+	return nil
+}
+
+func check(key, param string, v reflect.Value) string {
+	switch key {
+	case "required":
+		if isZero(v) {
+			return "is required"
+		}
+	case "min":
+		n := mustNumber(param)
+		if measure(v) < n {
+			return minMessage(v, param)
+		}
+	case "max":
+		n := mustNumber(param)
+		if measure(v) > n {
+			return maxMessage(v, param)
+		}
+	case "email":
+		if !emailRegex.MatchString(v.String()) {
+			return "must be a valid email address"
+		}
+	case "url":
+		if !isURL(v.String()) {
+			return "must be an http(s) URL or an absolute path"
+		}
+	case "oneof":
+		allowed := strings.Fields(param)
+		for _, a := range allowed {
+			if v.String() == a {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(allowed, ", ")
+	default:
+		panic("validate: unknown rule " + key)
+	}
+	return ""
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// measure returns the quantity min and max compare against
+func measure(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Map:
+		return float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic("validate: min/max on unsupported kind " + v.Kind().String())
+}
+
+func minMessage(v reflect.Value, param string) string {
+	switch v.Kind() {
+	case reflect.String:
+		return "must be at least " + param + " characters long"
+	case reflect.Slice, reflect.Map:
+		return "must contain at least " + param + " items"
+	}
+	return "must be at least " + param
+}
+
+func maxMessage(v reflect.Value, param string) string {
+	switch v.Kind() {
+	case reflect.String:
+		return "must be at most " + param + " characters long"
+	case reflect.Slice, reflect.Map:
+		return "must contain at most " + param + " items"
+	}
+	return "must be at most " + param
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		// Uploaded images are referenced by their path on this server
+		return u.Host == "" && strings.HasPrefix(u.Path, "/") && !strings.HasPrefix(s, "//")
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func mustNumber(param string) float64 {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("validate: bad rule parameter " + param)
+	}
+	return n
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
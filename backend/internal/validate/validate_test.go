@@ -0,0 +1,350 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+
+	"protein-web-backend/internal/types"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []types.FieldError
+	}{
+		// required
+		{
+			name: "required string present",
+			value: struct {
+				V string `validate:"required"`
+			}{"x"},
+		},
+		{
+			name: "required string empty",
+			value: struct {
+				V string `validate:"required"`
+			}{""},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+		{
+			name: "required string blank",
+			value: struct {
+				V string `validate:"required"`
+			}{" \t\n"},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+		{
+			name: "required slice empty",
+			value: struct {
+				V []string `validate:"required"`
+			}{[]string{}},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+		{
+			name: "required number zero",
+			value: struct {
+				V int `validate:"required"`
+			}{0},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+		{
+			name: "nil pointer skips required",
+			value: struct {
+				V *string `validate:"required,min=3"`
+			}{nil},
+		},
+		{
+			name: "pointer to blank is required",
+			value: struct {
+				V *string `validate:"required"`
+			}{ptr(" ")},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+		{
+			name: "nil slice pointer skips dive",
+			value: struct {
+				V *[]string `validate:"required,dive,oneof=a"`
+			}{nil},
+		},
+
+		// min and max count runes, not bytes
+		{
+			name: "max counts runes",
+			value: struct {
+				V string `validate:"max=3"`
+			}{"たんぱ"}, // 3 runes, 9 bytes
+		},
+		{
+			name: "max exceeded in runes",
+			value: struct {
+				V string `validate:"max=3"`
+			}{"たんぱく"},
+			want: []types.FieldError{{Field: "V", Message: "must be at most 3 characters long"}},
+		},
+		{
+			name: "min counts runes",
+			value: struct {
+				V string `validate:"min=3"`
+			}{"éé"}, // 2 runes, 4 bytes
+			want: []types.FieldError{{Field: "V", Message: "must be at least 3 characters long"}},
+		},
+		{
+			name: "min met",
+			value: struct {
+				V string `validate:"min=2"`
+			}{"éé"},
+		},
+		{
+			name: "empty optional string skips min",
+			value: struct {
+				V string `validate:"min=3"`
+			}{""},
+		},
+		{
+			name: "min on the value pointed to",
+			value: struct {
+				V *string `validate:"min=3"`
+			}{ptr("ab")},
+			want: []types.FieldError{{Field: "V", Message: "must be at least 3 characters long"}},
+		},
+		{
+			name: "max on slice length",
+			value: struct {
+				V []int `validate:"max=2"`
+			}{[]int{1, 2, 3}},
+			want: []types.FieldError{{Field: "V", Message: "must contain at most 2 items"}},
+		},
+		{
+			name: "min on slice length",
+			value: struct {
+				V []int `validate:"min=2"`
+			}{[]int{1}},
+			want: []types.FieldError{{Field: "V", Message: "must contain at least 2 items"}},
+		},
+		{
+			name: "max on int",
+			value: struct {
+				V int `validate:"max=5"`
+			}{6},
+			want: []types.FieldError{{Field: "V", Message: "must be at most 5"}},
+		},
+		{
+			name: "min on negative int",
+			value: struct {
+				V int `validate:"min=1"`
+			}{-1},
+			want: []types.FieldError{{Field: "V", Message: "must be at least 1"}},
+		},
+		{
+			name: "max on uint at the limit",
+			value: struct {
+				V uint8 `validate:"max=5"`
+			}{5},
+		},
+		{
+			name: "min on float",
+			value: struct {
+				V float64 `validate:"min=0.5"`
+			}{0.25},
+			want: []types.FieldError{{Field: "V", Message: "must be at least 0.5"}},
+		},
+
+		// email
+		{
+			name: "email valid",
+			value: struct {
+				V string `validate:"email"`
+			}{"user.name+tag@example.co.jp"},
+		},
+		{
+			name: "email without domain",
+			value: struct {
+				V string `validate:"email"`
+			}{"user@"},
+			want: []types.FieldError{{Field: "V", Message: "must be a valid email address"}},
+		},
+		{
+			name: "email without TLD",
+			value: struct {
+				V string `validate:"email"`
+			}{"user@localhost"},
+			want: []types.FieldError{{Field: "V", Message: "must be a valid email address"}},
+		},
+		{
+			name: "empty optional email",
+			value: struct {
+				V string `validate:"email"`
+			}{""},
+		},
+
+		// url
+		{
+			name: "https URL",
+			value: struct {
+				V string `validate:"url"`
+			}{"https://example.com/a.png"},
+		},
+		{
+			name: "root-relative path",
+			value: struct {
+				V string `validate:"url"`
+			}{"/uploads/a.png"},
+		},
+		{
+			name: "protocol-relative URL",
+			value: struct {
+				V string `validate:"url"`
+			}{"//evil.example/a.png"},
+			want: []types.FieldError{{Field: "V", Message: "must be an http(s) URL or an absolute path"}},
+		},
+		{
+			name: "other scheme",
+			value: struct {
+				V string `validate:"url"`
+			}{"javascript:alert(1)"},
+			want: []types.FieldError{{Field: "V", Message: "must be an http(s) URL or an absolute path"}},
+		},
+		{
+			name: "relative path",
+			value: struct {
+				V string `validate:"url"`
+			}{"uploads/a.png"},
+			want: []types.FieldError{{Field: "V", Message: "must be an http(s) URL or an absolute path"}},
+		},
+		{
+			name: "URL without host",
+			value: struct {
+				V string `validate:"url"`
+			}{"http://"},
+			want: []types.FieldError{{Field: "V", Message: "must be an http(s) URL or an absolute path"}},
+		},
+
+		// oneof
+		{
+			name: "oneof allowed",
+			value: struct {
+				V string `validate:"oneof=spam abuse other"`
+			}{"abuse"},
+		},
+		{
+			name: "oneof not allowed",
+			value: struct {
+				V string `validate:"oneof=spam abuse"`
+			}{"Spam"},
+			want: []types.FieldError{{Field: "V", Message: "must be one of spam, abuse"}},
+		},
+
+		// dive
+		{
+			name: "dive checks each element",
+			value: struct {
+				V []string `validate:"required,dive,oneof=a b"`
+			}{[]string{"a", "c", "b", "d"}},
+			want: []types.FieldError{
+				{Field: "V[1]", Message: "must be one of a, b"},
+				{Field: "V[3]", Message: "must be one of a, b"},
+			},
+		},
+		{
+			name: "rules before dive apply to the slice",
+			value: struct {
+				V []string `validate:"max=1,dive,min=2"`
+			}{[]string{"a", "b"}},
+			want: []types.FieldError{{Field: "V", Message: "must contain at most 1 items"}},
+		},
+		{
+			name: "dive through a pointer",
+			value: struct {
+				V *[]string `validate:"dive,url"`
+			}{ptr([]string{"/a.png", "a.png"})},
+			want: []types.FieldError{{Field: "V[1]", Message: "must be an http(s) URL or an absolute path"}},
+		},
+		{
+			name: "empty slice with required before dive",
+			value: struct {
+				V []string `validate:"required,dive,oneof=a"`
+			}{nil},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+
+		// reporting
+		{
+			name: "only the first failing rule is reported",
+			value: struct {
+				V string `validate:"required,min=3,email"`
+			}{"ab"},
+			want: []types.FieldError{{Field: "V", Message: "must be at least 3 characters long"}},
+		},
+		{
+			name: "fields use their JSON names",
+			value: struct {
+				A string `json:"alpha,omitempty" validate:"required"`
+				B string `json:"-" validate:"required"`
+				C string `validate:"required"`
+			}{},
+			want: []types.FieldError{
+				{Field: "alpha", Message: "is required"},
+				{Field: "B", Message: "is required"},
+				{Field: "C", Message: "is required"},
+			},
+		},
+		{
+			name: "untagged and unexported fields are skipped",
+			value: struct {
+				A string
+				b string `validate:"required"`
+			}{},
+		},
+		{
+			name: "pointer to struct",
+			value: &struct {
+				V string `validate:"required"`
+			}{},
+			want: []types.FieldError{{Field: "V", Message: "is required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Struct(tt.value)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructPanicsOnProgrammingErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "not a struct", value: "string"},
+		{name: "unknown rule", value: struct {
+			V string `validate:"uppercase"`
+		}{"x"}},
+		{name: "dive on a string", value: struct {
+			V string `validate:"dive,min=1"`
+		}{"x"}},
+		{name: "bad min parameter", value: struct {
+			V string `validate:"min=three"`
+		}{"x"}},
+		{name: "max on a bool", value: struct {
+			V bool `validate:"max=1"`
+		}{true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Struct did not panic")
+				}
+			}()
+			Struct(tt.value)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"protein-web-backend/internal/model"
+)
+
+func TestSenderSend(t *testing.T) {
+	event := &model.OutboxEvent{
+		Key:         "event-1",
+		Type:        model.EventReviewCreated,
+		AggregateID: 7,
+		Payload:     json.RawMessage(`{"id":7}`),
+	}
+
+	tests := []struct {
+		name       string
+		respond    func(w http.ResponseWriter)
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "2xx succeeds",
+			respond:    func(w http.ResponseWriter) { w.WriteHeader(http.StatusNoContent) },
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "5xx fails with the body",
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				io.WriteString(w, "  maintenance\n")
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantErr:    "webhook responded 503 Service Unavailable: maintenance",
+		},
+		{
+			name: "a long body is cut",
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusInternalServerError)
+				io.WriteString(w, strings.Repeat("x", 10000))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    ": " + strings.Repeat("x", maxErrorBody),
+		},
+		{
+			name: "redirects are not followed",
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Location", "/elsewhere")
+				w.WriteHeader(http.StatusFound)
+			},
+			wantStatus: http.StatusFound,
+			wantErr:    "webhook responded 302 Found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				body, _ := io.ReadAll(r.Body)
+				if err := Verify("secret", r.Header, body, time.Minute); err != nil {
+					t.Errorf("Verify: %v", err)
+				}
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+				}
+				if r.Header.Get(HeaderID) != event.Key || r.Header.Get(HeaderEvent) != event.Type {
+					t.Errorf("event headers = %q, %q", r.Header.Get(HeaderID), r.Header.Get(HeaderEvent))
+				}
+				var got model.OutboxEvent
+				if err := json.Unmarshal(body, &got); err != nil || got.Key != event.Key || string(got.Payload) != string(event.Payload) {
+					t.Errorf("body = %s (%v)", body, err)
+				}
+				tt.respond(w)
+			}))
+			defer receiver.Close()
+
+			status, err := NewSender(time.Second).Send(context.Background(), receiver.URL, "secret", event)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("err = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+			if requests != 1 {
+				t.Errorf("receiver got %d requests, want 1", requests)
+			}
+		})
+	}
+}
+
+func TestSenderSendUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	status, err := NewSender(time.Second).Send(context.Background(), url, "secret", &model.OutboxEvent{Key: "k", Type: "t"})
+	if status != 0 || err == nil {
+		t.Errorf("Send = %d, %v, want 0 and an error", status, err)
+	}
+}
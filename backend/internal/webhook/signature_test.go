@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("secret", `1700000000.{"a":1}`), computed independently
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got := Sign("secret", 1700000000, []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	now := time.Now().Unix()
+	signed := func(secret string, timestamp int64, body []byte) http.Header {
+		h := http.Header{}
+		h.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		h.Set(HeaderSignature, Sign(secret, timestamp, body))
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   error
+	}{
+		{name: "valid", header: signed("secret", now, body)},
+		{name: "within tolerance", header: signed("secret", now-200, body)},
+		{name: "wrong secret", header: signed("other", now, body), want: ErrInvalidSignature},
+		{name: "different body", header: signed("secret", now, []byte(`{"a":2}`)), want: ErrInvalidSignature},
+		{name: "too old", header: signed("secret", now-600, body), want: ErrStaleTimestamp},
+		{name: "too far ahead", header: signed("secret", now+600, body), want: ErrStaleTimestamp},
+		{name: "no headers", header: http.Header{}, want: ErrStaleTimestamp},
+		{
+			name: "timestamp changed after signing",
+			header: func() http.Header {
+				h := signed("secret", now, body)
+				h.Set(HeaderTimestamp, strconv.FormatInt(now-1, 10))
+				return h
+			}(),
+			want: ErrInvalidSignature,
+		},
+		{
+			name: "signature without prefix",
+			header: func() http.Header {
+				h := signed("secret", now, body)
+				h.Set(HeaderSignature, h.Get(HeaderSignature)[len(signaturePrefix):])
+				return h
+			}(),
+			want: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify("secret", tt.header, body, 5*time.Minute); !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE reviews DROP INDEX idx_hidden_at, DROP COLUMN hidden_at;
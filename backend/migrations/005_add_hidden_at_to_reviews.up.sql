@@ -0,0 +1,3 @@
+ALTER TABLE reviews
+    ADD COLUMN hidden_at TIMESTAMP NULL DEFAULT NULL AFTER comment,
+    ADD INDEX idx_hidden_at (hidden_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS moderation_results (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    review_id INT NULL,
+    user_id INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    original_text TEXT NOT NULL,
+    decisions JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE SET NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_action_created_at (action, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE users DROP INDEX idx_deleted_at, DROP COLUMN deleted_at;
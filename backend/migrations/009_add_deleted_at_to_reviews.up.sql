@@ -0,0 +1,3 @@
+ALTER TABLE reviews
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL AFTER updated_at,
+    ADD INDEX idx_deleted_at (deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE review_images DROP COLUMN deleted_at;
@@ -0,0 +1,2 @@
+ALTER TABLE review_images
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL AFTER created_at;
@@ -0,0 +1,3 @@
+ALTER TABLE reviews
+    DROP FOREIGN KEY fk_reviews_user_id,
+    ADD CONSTRAINT reviews_ibfk_1 FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
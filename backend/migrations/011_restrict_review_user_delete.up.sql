@@ -0,0 +1,4 @@
+-- Users are soft-deleted; refuse hard deletes that would cascade to reviews
+ALTER TABLE reviews
+    DROP FOREIGN KEY reviews_ibfk_1,
+    ADD CONSTRAINT fk_reviews_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload JSON NOT NULL,
+    -- pending, running, done or dead
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    -- When a pending job becomes due; for a running job, when its lease
+    -- expires and another worker may take it over
+    run_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    locked_by VARCHAR(128) NULL,
+    last_error TEXT NULL,
+    -- Deduplicates enqueues, e.g. one run of a schedule per interval
+    unique_key VARCHAR(191) NULL,
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    finished_at TIMESTAMP(3) NULL,
+
+    UNIQUE KEY uq_unique_key (unique_key),
+    INDEX idx_status_run_at (status, run_at),
+    INDEX idx_status_finished_at (status, finished_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
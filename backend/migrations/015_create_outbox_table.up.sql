@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    -- Sent with every delivery so consumers can discard duplicates
+    event_key CHAR(32) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id INT NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    -- When the relay may pick the event up next. While the relay is
+    -- publishing it, this is the end of the relay's lease.
+    next_attempt_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    last_error TEXT NULL,
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+    published_at TIMESTAMP(3) NULL,
+
+    UNIQUE KEY uq_event_key (event_key),
+    INDEX idx_published_at_next_attempt_at (published_at, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
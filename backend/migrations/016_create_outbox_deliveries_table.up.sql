@@ -0,0 +1,10 @@
+-- Sinks that already received an event, so a retry after a partial
+-- failure only goes to the sinks that failed
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    outbox_id BIGINT NOT NULL,
+    sink VARCHAR(64) NOT NULL,
+    delivered_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+
+    PRIMARY KEY (outbox_id, sink),
+    FOREIGN KEY (outbox_id) REFERENCES outbox(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
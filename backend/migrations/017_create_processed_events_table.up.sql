@@ -0,0 +1,9 @@
+-- Event keys each consumer has handled; delivery is at-least-once
+CREATE TABLE IF NOT EXISTS processed_events (
+    consumer VARCHAR(64) NOT NULL,
+    event_key CHAR(32) NOT NULL,
+    processed_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+
+    PRIMARY KEY (consumer, event_key),
+    INDEX idx_processed_at (processed_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,18 @@
+-- One row per attempt to deliver an event to a webhook
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    webhook_id INT NOT NULL,
+    event_key CHAR(32) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    attempt INT NOT NULL,
+    success BOOLEAN NOT NULL,
+    -- NULL when no response arrived
+    status_code INT NULL,
+    error TEXT NULL,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+
+    INDEX idx_webhook_id_created_at (webhook_id, created_at),
+    INDEX idx_created_at (created_at),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,18 @@
+-- Responses of requests sent with an Idempotency-Key, replayed when the
+-- client retries. user_id is 0 for anonymous requests such as registration.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id INT NOT NULL,
+    -- Binary so that keys differing only in case or trailing spaces stay distinct
+    idem_key VARBINARY(255) NOT NULL,
+    -- SHA-256 of the method, path and body; a retry must match it
+    fingerprint CHAR(64) NOT NULL,
+    -- NULL while the first request is still being handled
+    status_code INT NULL,
+    response_header JSON NULL,
+    response_body MEDIUMBLOB NULL,
+    created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    expires_at TIMESTAMP(3) NOT NULL,
+
+    PRIMARY KEY (user_id, idem_key),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
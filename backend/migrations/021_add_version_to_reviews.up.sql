@@ -0,0 +1,2 @@
+ALTER TABLE reviews
+    ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1 AFTER comment;
@@ -0,0 +1,4 @@
+ALTER TABLE outbox
+    DROP INDEX idx_published_at_dead_at_next_attempt_at,
+    ADD INDEX idx_published_at_next_attempt_at (published_at, next_attempt_at),
+    DROP COLUMN dead_at;
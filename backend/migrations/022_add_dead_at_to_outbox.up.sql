@@ -0,0 +1,7 @@
+-- Events that failed OUTBOX_MAX_ATTEMPTS times are dead-lettered: the relay
+-- no longer claims them, and they are kept until they are retried or
+-- deleted by hand
+ALTER TABLE outbox
+    ADD COLUMN dead_at TIMESTAMP(3) NULL AFTER published_at,
+    DROP INDEX idx_published_at_next_attempt_at,
+    ADD INDEX idx_published_at_dead_at_next_attempt_at (published_at, dead_at, next_attempt_at);
@@ -0,0 +1,34 @@
+// Package migrations embeds the SQL migration files so that the server knows
+// which schema version it was built for.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// LatestVersion returns the highest version number among the migration
+// files, i.e. the version migration_history must reach for this build.
+func LatestVersion() int {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return 0
+	}
+
+	latest := 0
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if version, err := strconv.Atoi(prefix); err == nil && version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
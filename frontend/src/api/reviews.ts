@@ -1,4 +1,5 @@
 import { ReviewFormData, Review } from "@/types/review";
+import { traceHeaders } from "@/lib/tracing";
 
 const API_BASE_URL = import.meta.env.VITE_API_URL || "http://localhost:8080";
 
@@ -39,11 +40,12 @@ export const reviewApi = {
       images: [], // 一旦空配列で送信
     };
 
-    const response = await fetch(`${API_BASE_URL}/api/reviews`, {
+    const response = await fetch(`${API_BASE_URL}/api/v1/reviews`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
         Authorization: `Bearer ${token}`,
+        ...traceHeaders(),
       },
       body: JSON.stringify(requestData),
     });
@@ -59,7 +61,8 @@ export const reviewApi = {
   // 全レビューを取得
   async getAllReviews(limit = 20, offset = 0): Promise<Review[]> {
     const response = await fetch(
-      `${API_BASE_URL}/api/reviews?limit=${limit}&offset=${offset}`,
+      `${API_BASE_URL}/api/v1/reviews?limit=${limit}&offset=${offset}`,
+      { headers: traceHeaders() },
     );
 
     if (!response.ok) {
@@ -71,7 +74,9 @@ export const reviewApi = {
 
   // 特定のレビューを取得
   async getReview(id: number): Promise<Review> {
-    const response = await fetch(`${API_BASE_URL}/api/reviews/${id}`);
+    const response = await fetch(`${API_BASE_URL}/api/v1/reviews/${id}`, {
+      headers: traceHeaders(),
+    });
 
     if (!response.ok) {
       throw new Error("レビューの取得に失敗しました");
@@ -87,7 +92,8 @@ export const reviewApi = {
     offset = 0,
   ): Promise<Review[]> {
     const response = await fetch(
-      `${API_BASE_URL}/api/users/${userId}/reviews?limit=${limit}&offset=${offset}`,
+      `${API_BASE_URL}/api/v1/users/${userId}/reviews?limit=${limit}&offset=${offset}`,
+      { headers: traceHeaders() },
     );
 
     if (!response.ok) {
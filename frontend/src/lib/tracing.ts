@@ -0,0 +1,14 @@
+// W3C Trace Context（https://www.w3.org/TR/trace-context/）の traceparent ヘッダーを生成する
+// バックエンドはこのトレースIDを引き継ぐので、ブラウザの操作とサーバー側のスパンを紐付けられる
+const randomHex = (bytes: number): string => {
+  const buf = new Uint8Array(bytes);
+  crypto.getRandomValues(buf);
+  return Array.from(buf, (b) => b.toString(16).padStart(2, "0")).join("");
+};
+
+export const traceparent = (): string =>
+  `00-${randomHex(16)}-${randomHex(8)}-01`;
+
+export const traceHeaders = (): Record<string, string> => ({
+  traceparent: traceparent(),
+});
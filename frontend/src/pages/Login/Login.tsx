@@ -86,7 +86,7 @@ export const Login: React.FC = () => {
     setErrors({});
 
     try {
-      const response = await fetch("/api/login", {
+      const response = await fetch("/api/v1/login", {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
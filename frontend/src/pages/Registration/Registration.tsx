@@ -82,7 +82,7 @@ export const Registration: React.FC = () => {
 
     try {
       // Step 1: Register user
-      const registerResponse = await fetch("/api/register", {
+      const registerResponse = await fetch("/api/v1/register", {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
@@ -95,7 +95,7 @@ export const Registration: React.FC = () => {
 
       if (registerResponse.ok) {
         // Step 2: Auto-login after successful registration
-        const loginResponse = await fetch("/api/login", {
+        const loginResponse = await fetch("/api/v1/login", {
           method: "POST",
           headers: {
             "Content-Type": "application/json",
@@ -1,3 +1,5 @@
+import { traceHeaders } from '@/lib/tracing';
+
 // API呼び出しのヘルパー関数
 interface ApiRequestOptions extends RequestInit {
   requireAuth?: boolean;
@@ -19,6 +21,7 @@ export const apiRequest = async (
   // デフォルトヘッダー
   const headers: Record<string, string> = {
     'Content-Type': 'application/json',
+    ...traceHeaders(),
     ...(fetchOptions.headers as Record<string, string>),
   };
 